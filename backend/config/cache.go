@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/go-redis/redis/v8"
 )
 
 // CacheService provides caching operations using Redis
@@ -43,13 +45,8 @@ func (c *CacheService) Exists(ctx context.Context, key string) (bool, error) {
 	return count > 0, err
 }
 
-// SetUserOnlineStatus sets a user's online status in Redis
-func (c *CacheService) SetUserOnlineStatus(ctx context.Context, userID uint, status string) error {
-	key := fmt.Sprintf("user:status:%d", userID)
-	return DB.Redis.Set(ctx, key, status, 24*time.Hour).Err()
-}
-
-// GetUserOnlineStatus gets a user's online status from Redis
+// GetUserOnlineStatus gets a user's online status from Redis.
+// The key is maintained by the presence service; a missing key (redis.Nil) means offline.
 func (c *CacheService) GetUserOnlineStatus(ctx context.Context, userID uint) (string, error) {
 	key := fmt.Sprintf("user:status:%d", userID)
 	return DB.Redis.Get(ctx, key).Result()
@@ -121,3 +118,48 @@ func (c *CacheService) ResetUnreadCount(ctx context.Context, userID uint, conver
 	key := fmt.Sprintf("user:%d:unread:%s", userID, conversationID)
 	return DB.Redis.Set(ctx, key, 0, 24*time.Hour).Err()
 }
+
+// SetUnreadCount overwrites unread message count (used when repairing drift)
+func (c *CacheService) SetUnreadCount(ctx context.Context, userID uint, conversationID string, count int64) error {
+	key := fmt.Sprintf("user:%d:unread:%s", userID, conversationID)
+	return DB.Redis.Set(ctx, key, count, 24*time.Hour).Err()
+}
+
+// GetUnreadCounts gets unread counts for many conversations in one round trip.
+// Missing keys are left out of the result so callers can repair them.
+func (c *CacheService) GetUnreadCounts(ctx context.Context, userID uint, conversationIDs []string) (map[string]int64, error) {
+	pipe := DB.Redis.Pipeline()
+	cmds := make(map[string]*redis.StringCmd, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		key := fmt.Sprintf("user:%d:unread:%s", userID, conversationID)
+		cmds[conversationID] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(conversationIDs))
+	for conversationID, cmd := range cmds {
+		count, err := cmd.Int64()
+		if err != nil {
+			continue
+		}
+		counts[conversationID] = count
+	}
+	return counts, nil
+}
+
+// AllowRate implements a fixed-window rate limiter.
+// It returns false once more than limit calls happen within the window.
+func (c *CacheService) AllowRate(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	key = fmt.Sprintf("ratelimit:%s", key)
+
+	// SetNX membuat counter beserta TTL hanya di awal window, Incr tidak mengubah TTL
+	pipe := DB.Redis.TxPipeline()
+	pipe.SetNX(ctx, key, 0, window)
+	incr := pipe.Incr(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return incr.Val() <= limit, nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetDuration membaca durasi dari env (contoh: "30s", "10m"), fallback jika kosong/invalid
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s value %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// GetInt membaca angka dari env, fallback jika kosong/invalid
+func GetInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Invalid %s value %q, using %d", key, value, fallback)
+		return fallback
+	}
+	return number
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CallController struct {
+	callUseCase *usecases.CallUseCase
+}
+
+func NewCallController(callUseCase *usecases.CallUseCase) *CallController {
+	return &CallController{
+		callUseCase: callUseCase,
+	}
+}
+
+func (cc *CallController) StartCall(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		CalleeID uint   `json:"callee_id" binding:"required"`
+		DeviceID string `json:"device_id" binding:"required"`
+		Media    string `json:"media"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Fields callee_id and device_id are required"})
+		return
+	}
+
+	call, err := cc.callUseCase.Start(userID, body.DeviceID, body.CalleeID, body.Media)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to start call: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Call started", "call": call})
+}
+
+func (cc *CallController) GetCall(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	call, err := cc.callUseCase.Get(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get call: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch call successfully", "call": call})
+}
+
+func (cc *CallController) AcceptCall(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		DeviceID string `json:"device_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field device_id is required"})
+		return
+	}
+
+	call, err := cc.callUseCase.Accept(userID, body.DeviceID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to accept call: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Call accepted", "call": call})
+}
+
+func (cc *CallController) DeclineCall(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	call, err := cc.callUseCase.Decline(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to decline call: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Call declined", "call": call})
+}
+
+func (cc *CallController) EndCall(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	call, err := cc.callUseCase.End(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to end call: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Call ended", "call": call})
+}
+
+// Signal dipakai client yang tidak memakai WebSocket, client WebSocket cukup mengirim event call.signal
+func (cc *CallController) Signal(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		DeviceID string          `json:"device_id" binding:"required"`
+		Type     string          `json:"type" binding:"required"`
+		Payload  json.RawMessage `json:"payload" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Fields device_id, type and payload are required"})
+		return
+	}
+
+	if err := cc.callUseCase.Signal(userID, body.DeviceID, c.Param("id"), body.Type, body.Payload); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to relay signal: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Signal relayed"})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContactController struct {
+	contactUseCase *usecases.ContactUseCase
+}
+
+func NewContactController(contactUseCase *usecases.ContactUseCase) *ContactController {
+	return &ContactController{
+		contactUseCase: contactUseCase,
+	}
+}
+
+// GetDiscoveryConfig memberi tahu client cara menghitung hash email sebelum diunggah
+func (cc *ContactController) GetDiscoveryConfig(c *gin.Context) {
+	config := cc.contactUseCase.Config()
+	c.JSON(200, gin.H{
+		"message":   "Fetch contact discovery config successfully",
+		"algorithm": "sha256",
+		"salt":      config.Salt,
+		"format":    "hex(sha256(salt + lowercase(trim(email))))",
+		"max_batch": config.MaxBatch,
+	})
+}
+
+func (cc *ContactController) Discover(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		Hashes []string `json:"hashes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field hashes is required"})
+		return
+	}
+
+	matches, err := cc.contactUseCase.Discover(userID, body.Hashes)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to discover contacts: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Contacts discovered successfully", "matches": matches})
+}
+
+func (cc *ContactController) SetDiscoverable(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		Discoverable *bool `json:"discoverable" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field discoverable is required"})
+		return
+	}
+
+	if err := cc.contactUseCase.SetDiscoverable(userID, *body.Discoverable); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update discoverability: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Discoverability updated", "discoverable": *body.Discoverable})
+}
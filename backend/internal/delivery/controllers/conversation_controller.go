@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConversationController struct {
+	conversationUseCase *usecases.ConversationUseCase
+	unreadUseCase       *usecases.UnreadUseCase
+}
+
+func NewConversationController(conversationUseCase *usecases.ConversationUseCase, unreadUseCase *usecases.UnreadUseCase) *ConversationController {
+	return &ConversationController{
+		conversationUseCase: conversationUseCase,
+		unreadUseCase:       unreadUseCase,
+	}
+}
+
+// GetOrCreateDM mengembalikan conversation DM dengan user lain, dibuat jika belum ada
+func (cc *ConversationController) GetOrCreateDM(c *gin.Context) {
+	userID := c.GetUint("id")
+	otherUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	conversation, err := cc.conversationUseCase.GetOrCreateDM(userID, uint(otherUserID))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get conversation: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch conversation successfully", "conversation": conversation})
+}
+
+func (cc *ConversationController) GetUnreadCount(c *gin.Context) {
+	userID := c.GetUint("id")
+	conversationID := c.Param("id")
+
+	count, err := cc.unreadUseCase.GetUnreadCount(userID, conversationID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get unread count: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch unread count successfully", "conversation_id": conversationID, "unread_count": count})
+}
+
+func (cc *ConversationController) MarkAsRead(c *gin.Context) {
+	userID := c.GetUint("id")
+	conversationID := c.Param("id")
+
+	if err := cc.unreadUseCase.MarkAsRead(userID, conversationID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to mark conversation as read: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Conversation marked as read"})
+}
+
+// UnreadBadge mengembalikan total unread semua conversation untuk badge icon aplikasi
+func (cc *ConversationController) UnreadBadge(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	total, err := cc.unreadUseCase.TotalUnread(userID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to get unread badge: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch unread badge successfully", "total_unread": total})
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"errors"
+)
+
+// statusFromError memetakan error dari usecase ke status HTTP
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidID), errors.Is(err, usecases.ErrInvalidInput):
+		return 400
+	case errors.Is(err, usecases.ErrNotParticipant), errors.Is(err, usecases.ErrNotFriends),
+		errors.Is(err, usecases.ErrNotMember), errors.Is(err, usecases.ErrForbidden),
+		errors.Is(err, usecases.ErrBlocked), errors.Is(err, usecases.ErrRestricted):
+		return 403
+	case errors.Is(err, usecases.ErrNotFound):
+		return 404
+	case errors.Is(err, usecases.ErrConflict), errors.Is(err, usecases.ErrBusy):
+		return 409
+	case errors.Is(err, usecases.ErrRateLimited):
+		return 429
+	default:
+		return 500
+	}
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	exportUseCase *usecases.ExportUseCase
+}
+
+func NewExportController(exportUseCase *usecases.ExportUseCase) *ExportController {
+	return &ExportController{
+		exportUseCase: exportUseCase,
+	}
+}
+
+func (ec *ExportController) CreateExport(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		Format string `json:"format"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	job, err := ec.exportUseCase.CreateExport(userID, c.Param("id"), body.Format)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to create export: " + err.Error()})
+		return
+	}
+	c.JSON(202, gin.H{"message": "Export job created", "job": job})
+}
+
+func (ec *ExportController) GetExport(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	job, token, err := ec.exportUseCase.GetJob(userID, c.Param("job_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get export: " + err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Fetch export successfully", "job": job}
+	if token != "" {
+		response["download_url"] = "/api/exports/download/" + token
+	}
+	c.JSON(200, response)
+}
+
+// Download tidak memakai AuthMiddleware, token di URL sudah berfungsi sebagai otorisasi
+func (ec *ExportController) Download(c *gin.Context) {
+	file, size, filename, err := ec.exportUseCase.OpenDownload(c.Param("token"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to download export: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Content-Length", fmt.Sprint(size))
+	c.Header("Content-Type", "application/zip")
+	c.Status(200)
+	io.Copy(c.Writer, file)
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/repositories"
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FriendController struct {
+	friendshipUseCase *usecases.FriendshipUseCase
+	suggestionUseCase *usecases.SuggestionUseCase
+}
+
+func NewFriendController(friendshipUseCase *usecases.FriendshipUseCase, suggestionUseCase *usecases.SuggestionUseCase) *FriendController {
+	return &FriendController{
+		friendshipUseCase: friendshipUseCase,
+		suggestionUseCase: suggestionUseCase,
+	}
+}
+
+// parseUintParam membaca ID dari path, menulis response 400 jika tidak valid
+func parseUintParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": message})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (fc *FriendController) list(c *gin.Context, kind string) {
+	userID := c.GetUint("id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit = usecases.ListPage(page, limit)
+
+	friendships, total, err := fc.friendshipUseCase.List(userID, kind, page, limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch friends: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message":     "Fetch friends successfully",
+		"friendships": friendships,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+func (fc *FriendController) GetFriends(c *gin.Context) {
+	fc.list(c, repositories.FriendshipListAccepted)
+}
+
+func (fc *FriendController) GetIncomingRequests(c *gin.Context) {
+	fc.list(c, repositories.FriendshipListIncoming)
+}
+
+func (fc *FriendController) GetOutgoingRequests(c *gin.Context) {
+	fc.list(c, repositories.FriendshipListOutgoing)
+}
+
+func (fc *FriendController) SendRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field user_id is required"})
+		return
+	}
+
+	friendship, err := fc.friendshipUseCase.SendRequest(userID, body.UserID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to send friend request: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Friend request sent", "friendship": friendship})
+}
+
+func (fc *FriendController) AcceptRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+	id, ok := parseUintParam(c, "id", "Invalid friend request id")
+	if !ok {
+		return
+	}
+
+	friendship, err := fc.friendshipUseCase.Accept(userID, id)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to accept friend request: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Friend request accepted", "friendship": friendship})
+}
+
+func (fc *FriendController) DeclineRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+	id, ok := parseUintParam(c, "id", "Invalid friend request id")
+	if !ok {
+		return
+	}
+
+	if err := fc.friendshipUseCase.Decline(userID, id); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to decline friend request: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Friend request declined"})
+}
+
+func (fc *FriendController) CancelRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+	id, ok := parseUintParam(c, "id", "Invalid friend request id")
+	if !ok {
+		return
+	}
+
+	if err := fc.friendshipUseCase.Cancel(userID, id); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to cancel friend request: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Friend request cancelled"})
+}
+
+func (fc *FriendController) Unfriend(c *gin.Context) {
+	userID := c.GetUint("id")
+	friendID, ok := parseUintParam(c, "user_id", "Invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := fc.friendshipUseCase.Unfriend(userID, friendID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to unfriend user: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Friend removed"})
+}
+
+func (fc *FriendController) GetBlocked(c *gin.Context) {
+	fc.list(c, repositories.FriendshipListBlocked)
+}
+
+func (fc *FriendController) Block(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field user_id is required"})
+		return
+	}
+
+	block, err := fc.friendshipUseCase.Block(userID, body.UserID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to block user: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "User blocked", "friendship": block})
+}
+
+func (fc *FriendController) Unblock(c *gin.Context) {
+	userID := c.GetUint("id")
+	targetID, ok := parseUintParam(c, "user_id", "Invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := fc.friendshipUseCase.Unblock(userID, targetID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to unblock user: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "User unblocked"})
+}
+
+func (fc *FriendController) GetSuggestions(c *gin.Context) {
+	userID := c.GetUint("id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	suggestions, err := fc.suggestionUseCase.List(userID, limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch friend suggestions: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch friend suggestions successfully", "suggestions": suggestions})
+}
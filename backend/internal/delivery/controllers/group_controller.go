@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupController struct {
+	groupUseCase      *usecases.GroupUseCase
+	permissionUseCase *usecases.GroupPermissionUseCase
+}
+
+func NewGroupController(groupUseCase *usecases.GroupUseCase, permissionUseCase *usecases.GroupPermissionUseCase) *GroupController {
+	return &GroupController{
+		groupUseCase:      groupUseCase,
+		permissionUseCase: permissionUseCase,
+	}
+}
+
+func (gc *GroupController) GetGroups(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit = usecases.ListPage(page, limit)
+
+	groups, total, err := gc.groupUseCase.List(userID, page, limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch groups: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message": "Fetch groups successfully",
+		"groups":  groups,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+func (gc *GroupController) CreateGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var input usecases.GroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	group, err := gc.groupUseCase.Create(userID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to create group: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Group created", "group": group})
+}
+
+func (gc *GroupController) GetGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	detail, err := gc.groupUseCase.Get(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get group: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch group successfully", "group": detail.Group, "role": detail.Role, "members": detail.Members})
+}
+
+func (gc *GroupController) PreviewGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	preview, err := gc.groupUseCase.Preview(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to preview group: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch group successfully", "group": preview})
+}
+
+func (gc *GroupController) UpdateGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	var input usecases.GroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	group, err := gc.groupUseCase.Update(userID, groupID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update group: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Group updated", "group": group})
+}
+
+func (gc *GroupController) DeleteGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	if err := gc.groupUseCase.Delete(userID, groupID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to delete group: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Group deleted"})
+}
+
+func (gc *GroupController) AddMembers(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		UserIDs []uint `json:"user_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field user_ids is required"})
+		return
+	}
+
+	added, err := gc.groupUseCase.AddMembers(userID, groupID, body.UserIDs)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to add members: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Members added", "user_ids": added})
+}
+
+func (gc *GroupController) RemoveMember(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseUintParam(c, "user_id", "Invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := gc.groupUseCase.RemoveMember(userID, groupID, memberID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to remove member: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Member removed"})
+}
+
+func (gc *GroupController) UpdateMemberRole(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseUintParam(c, "user_id", "Invalid user id")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field role is required"})
+		return
+	}
+
+	member, err := gc.groupUseCase.UpdateMemberRole(userID, groupID, memberID, body.Role)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update member role: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Member role updated", "member": member})
+}
+
+func (gc *GroupController) LeaveGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	if err := gc.groupUseCase.Leave(userID, groupID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to leave group: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Left group"})
+}
+
+func (gc *GroupController) TransferOwnership(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field user_id is required"})
+		return
+	}
+
+	group, err := gc.groupUseCase.TransferOwnership(userID, groupID, body.UserID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to transfer ownership: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Ownership transferred", "group": group})
+}
+
+func (gc *GroupController) GetPermissions(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	role, permissions, err := gc.permissionUseCase.Effective(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get permissions: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch permissions successfully", "role": role, "permissions": permissions})
+}
+
+func (gc *GroupController) GetRoles(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	roles, err := gc.permissionUseCase.Roles(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get role permissions: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch role permissions successfully", "roles": roles})
+}
+
+func (gc *GroupController) UpdateRole(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Permissions map[string]bool `json:"permissions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field permissions is required"})
+		return
+	}
+
+	permissions, err := gc.permissionUseCase.UpdateRole(userID, groupID, c.Param("role"), body.Permissions)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update role permissions: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Role permissions updated", "role": c.Param("role"), "permissions": permissions})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupInviteController struct {
+	inviteUseCase *usecases.GroupInviteUseCase
+}
+
+func NewGroupInviteController(inviteUseCase *usecases.GroupInviteUseCase) *GroupInviteController {
+	return &GroupInviteController{inviteUseCase: inviteUseCase}
+}
+
+func (ic *GroupInviteController) GetInvites(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	invites, err := ic.inviteUseCase.List(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch invites: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch invites successfully", "invites": invites})
+}
+
+func (ic *GroupInviteController) CreateInvite(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	var input usecases.GroupInviteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	invite, err := ic.inviteUseCase.Create(userID, groupID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to create invite: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Invite created", "invite": invite})
+}
+
+func (ic *GroupInviteController) RevokeInvite(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	inviteID, ok := parseUintParam(c, "invite_id", "Invalid invite id")
+	if !ok {
+		return
+	}
+
+	if err := ic.inviteUseCase.Revoke(userID, groupID, inviteID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to revoke invite: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Invite revoked"})
+}
+
+func (ic *GroupInviteController) GetInviteUses(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	inviteID, ok := parseUintParam(c, "invite_id", "Invalid invite id")
+	if !ok {
+		return
+	}
+
+	uses, err := ic.inviteUseCase.Uses(userID, groupID, inviteID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch invite uses: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch invite uses successfully", "uses": uses})
+}
+
+func (ic *GroupInviteController) PreviewInvite(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	preview, err := ic.inviteUseCase.Preview(userID, c.Param("token"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to preview invite: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch invite successfully", "invite": preview})
+}
+
+func (ic *GroupInviteController) JoinInvite(c *gin.Context) {
+	userID := c.GetUint("id")
+	answers, ok := bindJoinAnswers(c)
+	if !ok {
+		return
+	}
+
+	status, group, err := ic.inviteUseCase.Join(userID, c.Param("token"), answers)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to join group: " + err.Error()})
+		return
+	}
+	if status != models.GroupInviteUseJoined {
+		c.JSON(202, gin.H{"message": "Join request sent", "status": status, "group_id": group.ID})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Joined group", "status": status, "group": group})
+}
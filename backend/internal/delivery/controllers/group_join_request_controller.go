@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/usecases"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupJoinRequestController struct {
+	joinRequestUseCase *usecases.GroupJoinRequestUseCase
+}
+
+func NewGroupJoinRequestController(joinRequestUseCase *usecases.GroupJoinRequestUseCase) *GroupJoinRequestController {
+	return &GroupJoinRequestController{joinRequestUseCase: joinRequestUseCase}
+}
+
+// bindJoinAnswers membaca jawaban pertanyaan join dari body, body boleh kosong
+func bindJoinAnswers(c *gin.Context) ([]string, bool) {
+	var body struct {
+		Answers []string `json:"answers"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return nil, false
+	}
+	return body.Answers, true
+}
+
+func (jc *GroupJoinRequestController) JoinGroup(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	answers, ok := bindJoinAnswers(c)
+	if !ok {
+		return
+	}
+
+	status, group, err := jc.joinRequestUseCase.Join(userID, groupID, answers)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to join group: " + err.Error()})
+		return
+	}
+	if status != models.GroupInviteUseJoined {
+		c.JSON(202, gin.H{"message": "Join request sent", "status": status, "group_id": group.ID})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Joined group", "status": status, "group": group})
+}
+
+func (jc *GroupJoinRequestController) GetJoinRequests(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit = usecases.ListPage(page, limit)
+
+	requests, total, err := jc.joinRequestUseCase.List(userID, groupID, page, limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch join requests: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message":  "Fetch join requests successfully",
+		"requests": requests,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+func (jc *GroupJoinRequestController) ApproveJoinRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	requestID, ok := parseUintParam(c, "request_id", "Invalid join request id")
+	if !ok {
+		return
+	}
+
+	request, err := jc.joinRequestUseCase.Approve(userID, groupID, requestID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to approve join request: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Join request approved", "request": request})
+}
+
+func (jc *GroupJoinRequestController) RejectJoinRequest(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseUintParam(c, "id", "Invalid group id")
+	if !ok {
+		return
+	}
+	requestID, ok := parseUintParam(c, "request_id", "Invalid join request id")
+	if !ok {
+		return
+	}
+
+	request, err := jc.joinRequestUseCase.Reject(userID, groupID, requestID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to reject join request: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Join request rejected", "request": request})
+}
@@ -1 +1,148 @@
-package controllers
\ No newline at end of file
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MessageController struct {
+	messageUseCase *usecases.MessageUseCase
+}
+
+func NewMessageController(messageUseCase *usecases.MessageUseCase) *MessageController {
+	return &MessageController{
+		messageUseCase: messageUseCase,
+	}
+}
+
+func (mc *MessageController) Send(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	input := usecases.SendMessageInput{}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	message, conversation, err := mc.messageUseCase.Send(userID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to send message: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Message sent successfully", "data": message, "conversation_id": conversation.ID.Hex()})
+}
+
+func (mc *MessageController) Edit(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		Content string `json:"content"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	message, err := mc.messageUseCase.Edit(userID, c.Param("id"), body.Content)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to edit message: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Message edited successfully", "data": message})
+}
+
+func (mc *MessageController) Delete(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	message, err := mc.messageUseCase.Delete(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to delete message: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Message deleted successfully", "data": message})
+}
+
+func (mc *MessageController) ListByConversation(c *gin.Context) {
+	userID := c.GetUint("id")
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 64)
+
+	messages, err := mc.messageUseCase.ListMessages(userID, c.Param("id"), c.Query("before"), limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get messages: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch messages successfully", "messages": messages})
+}
+
+// parseTimeQuery membaca query parameter waktu dengan format RFC3339
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (mc *MessageController) Search(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	filter := models.MessageSearchFilter{
+		Query:         c.Query("q"),
+		Type:          c.Query("type"),
+		HasAttachment: c.Query("has_attachment") == "true",
+	}
+
+	if from := c.Query("from"); from != "" {
+		senderID, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid from user id"})
+			return
+		}
+		sender := uint(senderID)
+		filter.SenderID = &sender
+	}
+
+	var err error
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid since, use RFC3339 format"})
+		return
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid until, use RFC3339 format"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid page"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+	page, limit = usecases.SearchPage(page, limit)
+	filter.Page, filter.Limit = int64(page), int64(limit)
+
+	results, total, err := mc.messageUseCase.Search(userID, c.Query("conversation_id"), filter)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to search messages: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message": "Messages searched successfully",
+		"results": results,
+		"total":   total,
+		"page":    filter.Page,
+		"limit":   filter.Limit,
+	})
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PresenceController struct {
+	presenceUseCase *usecases.PresenceUseCase
+}
+
+func NewPresenceController(presenceUseCase *usecases.PresenceUseCase) *PresenceController {
+	return &PresenceController{
+		presenceUseCase: presenceUseCase,
+	}
+}
+
+// Heartbeat dipakai client yang tidak memakai WebSocket (SSE atau aplikasi di background)
+func (pc *PresenceController) Heartbeat(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		DeviceID string `json:"device_id" binding:"required"`
+		Active   *bool  `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field device_id is required"})
+		return
+	}
+
+	active := body.Active == nil || *body.Active
+	if err := pc.presenceUseCase.Heartbeat(userID, body.DeviceID, active); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to record heartbeat: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Heartbeat recorded"})
+}
+
+// GetFriends mengambil daftar teman beserta status online dan last seen
+func (pc *PresenceController) GetFriends(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	sortBy := c.DefaultQuery("sort", usecases.FriendSortOnline)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit = usecases.ListPage(page, limit)
+
+	friends, total, err := pc.presenceUseCase.ListFriends(userID, sortBy, page, limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to fetch friends: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message": "Fetch friends successfully",
+		"friends": friends,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyController struct {
+	privacyUseCase *usecases.PrivacyUseCase
+}
+
+func NewPrivacyController(privacyUseCase *usecases.PrivacyUseCase) *PrivacyController {
+	return &PrivacyController{
+		privacyUseCase: privacyUseCase,
+	}
+}
+
+func (pc *PrivacyController) GetSettings(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	settings, err := pc.privacyUseCase.Get(userID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get privacy settings: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch privacy settings successfully", "settings": settings})
+}
+
+func (pc *PrivacyController) UpdateSettings(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var input usecases.PrivacyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	settings, err := pc.privacyUseCase.Update(userID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update privacy settings: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Privacy settings updated", "settings": settings})
+}
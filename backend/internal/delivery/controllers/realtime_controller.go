@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"echo-chat-app-backend/internal/delivery/realtime"
+	"echo-chat-app-backend/internal/usecases"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RealtimeController struct {
+	hub             *realtime.Hub
+	events          *realtime.EventLog
+	typingUseCase   *usecases.TypingUseCase
+	unreadUseCase   *usecases.UnreadUseCase
+	presenceUseCase *usecases.PresenceUseCase
+	callUseCase     *usecases.CallUseCase
+	roomUseCase     *usecases.RoomUseCase
+	ticketUseCase   *usecases.RealtimeTicketUseCase
+}
+
+func NewRealtimeController(hub *realtime.Hub, events *realtime.EventLog, typingUseCase *usecases.TypingUseCase, unreadUseCase *usecases.UnreadUseCase, presenceUseCase *usecases.PresenceUseCase, callUseCase *usecases.CallUseCase, roomUseCase *usecases.RoomUseCase, ticketUseCase *usecases.RealtimeTicketUseCase) *RealtimeController {
+	rc := &RealtimeController{
+		hub:             hub,
+		events:          events,
+		typingUseCase:   typingUseCase,
+		unreadUseCase:   unreadUseCase,
+		presenceUseCase: presenceUseCase,
+		callUseCase:     callUseCase,
+		roomUseCase:     roomUseCase,
+		ticketUseCase:   ticketUseCase,
+	}
+	rc.registerHandlers()
+	return rc
+}
+
+// registerHandlers memetakan event inbound dari client ke usecase
+func (rc *RealtimeController) registerHandlers() {
+	rc.hub.Handle("typing", func(userID uint, deviceID string, data json.RawMessage) error {
+		var payload struct {
+			ConversationID string `json:"conversation_id"`
+			Typing         bool   `json:"typing"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return errors.New("invalid typing payload")
+		}
+		return rc.typingUseCase.SetTyping(userID, payload.ConversationID, payload.Typing)
+	})
+
+	rc.hub.Handle("read", func(userID uint, deviceID string, data json.RawMessage) error {
+		var payload struct {
+			ConversationID string `json:"conversation_id"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return errors.New("invalid read payload")
+		}
+		return rc.unreadUseCase.MarkAsRead(userID, payload.ConversationID)
+	})
+
+	rc.hub.Handle("heartbeat", func(userID uint, deviceID string, data json.RawMessage) error {
+		// tanpa payload dianggap user sedang aktif
+		payload := struct {
+			Active *bool `json:"active"`
+		}{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return errors.New("invalid heartbeat payload")
+			}
+		}
+		return rc.presenceUseCase.Heartbeat(userID, deviceID, payload.Active == nil || *payload.Active)
+	})
+
+	rc.hub.Handle("call.signal", func(userID uint, deviceID string, data json.RawMessage) error {
+		var payload struct {
+			CallID  string          `json:"call_id"`
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return errors.New("invalid call.signal payload")
+		}
+		return rc.callUseCase.Signal(userID, deviceID, payload.CallID, payload.Type, payload.Payload)
+	})
+
+	rc.hub.Handle("room.signal", func(userID uint, deviceID string, data json.RawMessage) error {
+		var payload struct {
+			GroupID  uint            `json:"group_id"`
+			ToUserID uint            `json:"to_user_id"`
+			Type     string          `json:"type"`
+			Payload  json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return errors.New("invalid room.signal payload")
+		}
+		return rc.roomUseCase.Signal(userID, deviceID, payload.GroupID, payload.ToUserID, payload.Type, payload.Payload)
+	})
+}
+
+// resolveDeviceID mengambil device_id dari query, client tanpa device_id diperlakukan
+// sebagai device terpisah per koneksi
+func resolveDeviceID(c *gin.Context) (string, bool) {
+	id := c.Query("device_id")
+	if len(id) > 64 {
+		c.JSON(400, gin.H{"error": "device_id is too long"})
+		return "", false
+	}
+	if id == "" {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			c.JSON(500, gin.H{"error": "Failed to generate device_id: " + err.Error()})
+			return "", false
+		}
+		id = "anon-" + hex.EncodeToString(buf)
+	}
+	return id, true
+}
+
+// resumeSeq mengambil seq terakhir yang sudah diterima client (query last_seq atau
+// header Last-Event-ID yang dikirim EventSource saat reconnect). nil berarti koneksi baru.
+func resumeSeq(c *gin.Context) (*uint64, bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_seq")
+	}
+	if value == "" {
+		return nil, true
+	}
+
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid last_seq"})
+		return nil, false
+	}
+	return &seq, true
+}
+
+// IssueTicket membuat ticket sekali pakai untuk membuka /ws atau /events dari browser
+func (rc *RealtimeController) IssueTicket(c *gin.Context) {
+	ticket, expiresAt, err := rc.ticketUseCase.Issue(c.GetUint("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to issue ticket: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Ticket issued successfully", "ticket": ticket, "expires_at": expiresAt})
+}
+
+// ServeWS membuka koneksi WebSocket untuk user yang sudah diautentikasi WSAuthMiddleware
+func (rc *RealtimeController) ServeWS(c *gin.Context) {
+	deviceID, ok := resolveDeviceID(c)
+	if !ok {
+		return
+	}
+	lastSeq, ok := resumeSeq(c)
+	if !ok {
+		return
+	}
+
+	// Upgrade menulis response error sendiri jika handshake gagal
+	realtime.ServeWS(rc.hub, rc.events, c.Writer, c.Request, c.GetUint("id"), deviceID, lastSeq)
+}
+
+// ServeSSE membuka stream Server-Sent Events, fallback untuk jaringan yang memblokir WebSocket
+func (rc *RealtimeController) ServeSSE(c *gin.Context) {
+	deviceID, ok := resolveDeviceID(c)
+	if !ok {
+		return
+	}
+	lastSeq, ok := resumeSeq(c)
+	if !ok {
+		return
+	}
+
+	if err := realtime.ServeSSE(rc.hub, rc.events, c.Writer, c.Request, c.GetUint("id"), deviceID, lastSeq); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to open event stream: " + err.Error()})
+	}
+}
+
+// Metrics mengembalikan metrik antrian kirim semua koneksi realtime di instance ini
+func (rc *RealtimeController) Metrics(c *gin.Context) {
+	c.JSON(200, gin.H{"message": "Fetch realtime metrics successfully", "metrics": rc.hub.Stats()})
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomController struct {
+	roomUseCase *usecases.RoomUseCase
+}
+
+func NewRoomController(roomUseCase *usecases.RoomUseCase) *RoomController {
+	return &RoomController{
+		roomUseCase: roomUseCase,
+	}
+}
+
+// parseGroupID membaca ID grup dari path, menulis response 400 jika tidak valid
+func parseGroupID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid group id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (rc *RoomController) GetRoom(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	room, err := rc.roomUseCase.Get(userID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get room: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch room successfully", "room": room})
+}
+
+// bindDeviceID membaca device_id dari body, menulis response 400 jika kosong
+func bindDeviceID(c *gin.Context) (string, bool) {
+	var body struct {
+		DeviceID string `json:"device_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Field device_id is required"})
+		return "", false
+	}
+	return body.DeviceID, true
+}
+
+func (rc *RoomController) CreateRoom(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+	deviceID, ok := bindDeviceID(c)
+	if !ok {
+		return
+	}
+
+	room, err := rc.roomUseCase.Create(userID, deviceID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to create room: " + err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{"message": "Room created", "room": room})
+}
+
+func (rc *RoomController) JoinRoom(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+	deviceID, ok := bindDeviceID(c)
+	if !ok {
+		return
+	}
+
+	room, err := rc.roomUseCase.Join(userID, deviceID, groupID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to join room: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Joined room", "room": room})
+}
+
+func (rc *RoomController) LeaveRoom(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := rc.roomUseCase.Leave(userID, groupID); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to leave room: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Left room"})
+}
+
+func (rc *RoomController) UpdateState(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var input usecases.RoomStateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	room, err := rc.roomUseCase.UpdateState(userID, groupID, input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update room state: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Room state updated", "room": room})
+}
+
+// Signal dipakai client yang tidak memakai WebSocket, client WebSocket cukup mengirim event room.signal
+func (rc *RoomController) Signal(c *gin.Context) {
+	userID := c.GetUint("id")
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		DeviceID string          `json:"device_id" binding:"required"`
+		ToUserID uint            `json:"to_user_id" binding:"required"`
+		Type     string          `json:"type" binding:"required"`
+		Payload  json.RawMessage `json:"payload" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Fields device_id, to_user_id, type and payload are required"})
+		return
+	}
+
+	if err := rc.roomUseCase.Signal(userID, body.DeviceID, groupID, body.ToUserID, body.Type, body.Payload); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to relay signal: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Signal relayed"})
+}
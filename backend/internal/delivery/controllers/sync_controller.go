@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SyncController struct {
+	syncUseCase *usecases.SyncUseCase
+}
+
+func NewSyncController(syncUseCase *usecases.SyncUseCase) *SyncController {
+	return &SyncController{
+		syncUseCase: syncUseCase,
+	}
+}
+
+// Sync mengembalikan perubahan sejak sync token (kosong untuk sinkronisasi pertama)
+func (sc *SyncController) Sync(c *gin.Context) {
+	userID := c.GetUint("id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	page, err := sc.syncUseCase.Sync(userID, c.Query("token"), limit)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to sync: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message":    "Sync successfully",
+		"changes":    page.Changes,
+		"next_token": page.NextToken,
+		"has_more":   page.HasMore,
+	})
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"echo-chat-app-backend/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TypingController struct {
+	typingUseCase *usecases.TypingUseCase
+}
+
+func NewTypingController(typingUseCase *usecases.TypingUseCase) *TypingController {
+	return &TypingController{
+		typingUseCase: typingUseCase,
+	}
+}
+
+func (tc *TypingController) SetTyping(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	var body struct {
+		Typing *bool `json:"typing"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Typing == nil {
+		c.JSON(400, gin.H{"error": "Field typing (true/false) is required"})
+		return
+	}
+
+	if err := tc.typingUseCase.SetTyping(userID, c.Param("id"), *body.Typing); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to update typing status: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Typing status updated"})
+}
+
+func (tc *TypingController) ListTyping(c *gin.Context) {
+	userID := c.GetUint("id")
+
+	typers, err := tc.typingUseCase.ListTyping(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to get typing users: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Fetch typing users successfully", "typing": typers})
+}
@@ -27,10 +27,11 @@ func (uc *UserController) Me(c *gin.Context) {
 }
 
 func (uc *UserController) SearchUserByUsername(c *gin.Context) {
+	userID := c.GetUint("id")
 	username := c.Query("username")
-	user, err := uc.userUseCase.SearchUserByUsername(username)
+	user, err := uc.userUseCase.SearchUserByUsername(userID, username)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to search user: " + err.Error()})
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to search user: " + err.Error()})
 		return
 	}
 	c.JSON(200, gin.H{"message": "User searched successfully", "user": user})
@@ -50,4 +51,15 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 		return
 	}
 	c.JSON(200, gin.H{"message": "User updated successfully", "user": user})
-}	
+}
+
+func (uc *UserController) DeleteAccount(c *gin.Context) {
+	userID := c.GetUint("id")
+	uid := c.GetString("firebase_uid")
+
+	if err := uc.userUseCase.DeleteAccount(userID, uid); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": "Failed to delete account: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "Account deleted"})
+}
@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/usecases"
 	"strings"
 
 	"firebase.google.com/go/v4/auth"
@@ -14,47 +15,93 @@ func AuthMiddleware(mysqlDB *gorm.DB, authClient *auth.Client) gin.HandlerFunc {
 		// 1. Ambil header Authorization
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "Authorization header missing"})
+			c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header missing"})
 			return
 		}
 
 		// 2. Cek format header apakah ada "Bearer"
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(401, gin.H{"error": "Invalid Authorization header format"})
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid Authorization header format"})
 			return
 		}
 
 		// 3. Ambil token dari header
 		idToken := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// 4. Verifikasi token menggunakan Firebase Auth
-		token, err := authClient.VerifyIDToken(c, idToken)
-		if err != nil {
-			c.JSON(401, gin.H{"error": "Invalid or expired token"})
+		if !authenticate(c, mysqlDB, authClient, idToken) {
 			return
 		}
 
-		// 5. Cek apakah email ada di token
-		if token.Claims["email"] == nil {
-			c.JSON(401, gin.H{"error": "Email not found in token"})
+		c.Next()
+	}
+}
+
+// WSAuthMiddleware sama seperti AuthMiddleware, tapi juga menerima ticket sekali pakai
+// dari query parameter "ticket" karena browser tidak bisa mengirim header saat membuka
+// WebSocket atau EventSource. Ticket didapat dari POST /realtime/ticket sehingga ID token
+// tidak pernah muncul di URL.
+func WSAuthMiddleware(mysqlDB *gorm.DB, authClient *auth.Client, ticketUseCase *usecases.RealtimeTicketUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			if !authenticate(c, mysqlDB, authClient, strings.TrimPrefix(authHeader, "Bearer ")) {
+				return
+			}
+			c.Next()
 			return
 		}
-		// cari user di database
-		user := models.User{}
-		err = mysqlDB.Where("email = ?", token.Claims["email"]).First(&user).Error
+
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Authorization ticket missing"})
+			return
+		}
+		userID, err := ticketUseCase.Redeem(ticket)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "User not found"})
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired ticket"})
+			return
+		}
+		user := models.User{}
+		if err := mysqlDB.First(&user, userID).Error; err != nil {
+			c.AbortWithStatusJSON(401, gin.H{"error": "User not found"})
 			return
 		}
 
-		// 6. Simpan informasi user ke context
 		c.Set("id", user.ID)
-		c.Set("email", token.Claims["email"])
-		c.Set("firebase_uid", token.UID)
-		c.Set("name", token.Claims["name"])
-		c.Set("username", token.Claims["username"])
-		c.Set("avatar_url", token.Claims["picture"])
-
+		c.Set("email", user.Email)
 		c.Next()
 	}
 }
+
+// authenticate memverifikasi Firebase ID token dan menyimpan data user ke context.
+// Mengembalikan false (dan menghentikan request dengan 401) jika token tidak valid.
+func authenticate(c *gin.Context, mysqlDB *gorm.DB, authClient *auth.Client, idToken string) bool {
+	// 4. Verifikasi token menggunakan Firebase Auth
+	token, err := authClient.VerifyIDToken(c, idToken)
+	if err != nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired token"})
+		return false
+	}
+
+	// 5. Cek apakah email ada di token
+	if token.Claims["email"] == nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Email not found in token"})
+		return false
+	}
+	// cari user di database
+	user := models.User{}
+	err = mysqlDB.Where("email = ?", token.Claims["email"]).First(&user).Error
+	if err != nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "User not found"})
+		return false
+	}
+
+	// 6. Simpan informasi user ke context
+	c.Set("id", user.ID)
+	c.Set("email", token.Claims["email"])
+	c.Set("firebase_uid", token.UID)
+	c.Set("name", token.Claims["name"])
+	c.Set("username", token.Claims["username"])
+	c.Set("avatar_url", token.Claims["picture"])
+
+	return true
+}
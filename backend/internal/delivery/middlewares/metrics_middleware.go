@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware membatasi endpoint metrik operasional dengan token statis.
+// Endpoint dianggap tidak ada jika token tidak dikonfigurasi.
+func MetricsMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatus(404)
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+
+		c.Next()
+	}
+}
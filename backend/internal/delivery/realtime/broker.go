@@ -0,0 +1,144 @@
+package realtime
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const userChannelPrefix = "realtime:user:"
+
+// closeUserMessage adalah pesan kontrol di channel user yang meminta setiap instance menutup
+// koneksi user tersebut. Pesan ini tidak diteruskan ke client.
+const closeUserMessage = "close"
+
+func userChannel(userID uint) string {
+	return fmt.Sprintf("%s%d", userChannelPrefix, userID)
+}
+
+// Broker menyebarkan event antar instance backend lewat Redis Pub/Sub.
+// Setiap event dipublish ke channel milik user penerima, dan setiap instance hanya
+// subscribe ke channel user yang sedang terhubung ke instance tersebut.
+// Broker mengimplementasikan usecases.EventPublisher.
+type Broker struct {
+	hub         *Hub
+	events      *EventLog
+	redisClient *redis.Client
+	pubsub      *redis.PubSub
+
+	mu         sync.Mutex
+	subscribed map[uint]bool
+}
+
+func NewBroker(hub *Hub, events *EventLog, redisClient *redis.Client) *Broker {
+	broker := &Broker{
+		hub:         hub,
+		events:      events,
+		redisClient: redisClient,
+		pubsub:      redisClient.Subscribe(context.Background()),
+		subscribed:  map[uint]bool{},
+	}
+	hub.OnUser(broker.syncSubscription, broker.syncSubscription)
+	return broker
+}
+
+// Run membaca pesan dari Redis dan mengantarkannya ke koneksi lokal.
+// go-redis otomatis reconnect dan subscribe ulang channel yang aktif.
+func (b *Broker) Run() {
+	for message := range b.pubsub.Channel() {
+		userID, err := strconv.ParseUint(strings.TrimPrefix(message.Channel, userChannelPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if message.Payload == closeUserMessage {
+			b.hub.closeUser(uint(userID))
+			continue
+		}
+
+		// seq dibutuhkan untuk melewati event yang sudah terkirim saat handshake
+		var meta struct {
+			Seq uint64 `json:"seq"`
+		}
+		json.Unmarshal([]byte(message.Payload), &meta)
+		b.hub.deliver(uint(userID), frame{Seq: meta.Seq, Data: []byte(message.Payload)})
+	}
+}
+
+// PublishToUsers menyimpan event ke event log penerima lalu mempublish-nya
+// ke channel setiap user penerima dalam satu pipeline
+func (b *Broker) PublishToUsers(userIDs []uint, event models.Event) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	frames, err := b.events.Append(userIDs, event)
+	if err != nil {
+		// Redis tidak tersedia: minimal antarkan ke koneksi di instance ini (tanpa seq, tidak bisa di-resume)
+		log.Printf("Failed to store realtime event %s: %v", event.Type, err)
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		for _, userID := range userIDs {
+			b.hub.deliver(userID, frame{Data: data})
+		}
+		return
+	}
+
+	ctx := context.Background()
+	pipe := b.redisClient.Pipeline()
+	for i, userID := range userIDs {
+		pipe.Publish(ctx, userChannel(userID), frames[i].Data)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to publish realtime event %s: %v", event.Type, err)
+		for i, userID := range userIDs {
+			b.hub.deliver(userID, frames[i])
+		}
+	}
+}
+
+// CloseUser menutup semua koneksi realtime userID di semua instance. Instance yang memegang
+// koneksi user pasti subscribe ke channel user tersebut.
+func (b *Broker) CloseUser(userID uint) {
+	if err := b.redisClient.Publish(context.Background(), userChannel(userID), closeUserMessage).Err(); err != nil {
+		log.Printf("Failed to publish close of user %d: %v", userID, err)
+		b.hub.closeUser(userID)
+	}
+}
+
+// syncSubscription menyamakan subscription Redis dengan status koneksi lokal user.
+// Status dibaca ulang di bawah lock, sehingga connect/disconnect yang berdekatan
+// tidak bisa meninggalkan user yang terhubung dalam keadaan unsubscribed.
+func (b *Broker) syncSubscription(userID uint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	connected := b.hub.IsConnected(userID)
+	if connected == b.subscribed[userID] {
+		return
+	}
+
+	ctx := context.Background()
+	if connected {
+		if err := b.pubsub.Subscribe(ctx, userChannel(userID)); err != nil {
+			log.Printf("Failed to subscribe realtime channel of user %d: %v", userID, err)
+			return
+		}
+		b.subscribed[userID] = true
+		return
+	}
+
+	if err := b.pubsub.Unsubscribe(ctx, userChannel(userID)); err != nil {
+		log.Printf("Failed to unsubscribe realtime channel of user %d: %v", userID, err)
+		return
+	}
+	delete(b.subscribed, userID)
+}
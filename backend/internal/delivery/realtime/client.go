@@ -0,0 +1,168 @@
+package realtime
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 64 * 1024
+
+	// aliveInterval membatasi seberapa sering frame dari client memperbarui presence
+	aliveInterval = 15 * time.Second
+)
+
+// CloseSlowConsumer adalah close code WebSocket untuk koneksi yang diputus karena antrian
+// kirimnya penuh. Event yang belum terkirim tetap ada di event log, jadi client cukup
+// reconnect dengan last_seq.
+const CloseSlowConsumer = 4000
+
+// CloseAccountDeleted adalah close code WebSocket untuk koneksi yang diputus karena akun
+// user dihapus. Client tidak perlu reconnect.
+const CloseAccountDeleted = 4001
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Autentikasi memakai bearer token (bukan cookie), jadi origin mana pun diizinkan seperti cors.Default()
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Client adalah satu koneksi WebSocket milik satu device user
+type Client struct {
+	*connection
+	hub  *Hub
+	conn *websocket.Conn
+
+	// hanya dipakai goroutine readPump
+	aliveAt     time.Time
+	aliveActive bool
+}
+
+// ServeWS meng-upgrade request HTTP menjadi WebSocket dan menjalankan pump baca/tulis.
+// lastSeq diisi jika client melanjutkan stream sebelumnya.
+func ServeWS(hub *Hub, events *EventLog, w http.ResponseWriter, r *http.Request, userID uint, deviceID string, lastSeq *uint64) error {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &Client{
+		connection: newConnection(userID, deviceID, "websocket", hub.queueSize, hub.slowGrace),
+		hub:        hub,
+		conn:       conn,
+	}
+	hub.register(client.connection)
+	initial := client.handshake(events, pingPeriod, lastSeq)
+
+	go client.writePump(initial)
+	go client.readPump()
+	return nil
+}
+
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.unregister(c.connection)
+		c.close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.alive(false)
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// pesan apa pun dari client juga membuktikan koneksi masih hidup
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		inbound := models.InboundEvent{}
+		if err := json.Unmarshal(data, &inbound); err != nil || inbound.Type == "" {
+			c.alive(false)
+			c.sendEvent(models.NewEvent(models.EventError, models.ErrorEvent{Message: "invalid event format"}))
+			continue
+		}
+		// event heartbeat sudah memperbarui presence lewat handler-nya sendiri
+		if inbound.Type != "heartbeat" {
+			c.alive(inbound.Type != "ping")
+		}
+		c.hub.dispatch(c.connection, inbound)
+	}
+}
+
+// alive meneruskan tanda hidup koneksi ke hub, paling sering sekali per aliveInterval
+// kecuali frame aktif pertama setelah keepalive, agar status away langsung kembali online
+func (c *Client) alive(active bool) {
+	now := time.Now()
+	if now.Sub(c.aliveAt) < aliveInterval && (c.aliveActive || !active) {
+		return
+	}
+	c.aliveAt, c.aliveActive = now, active
+	c.hub.alive(c.connection, active)
+}
+
+// writePump mengirim frame handshake terlebih dahulu, lalu frame dari antrian
+func (c *Client) writePump(initial []frame) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for _, f := range initial {
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.conn.WriteMessage(websocket.TextMessage, f.Data); err != nil {
+			c.close()
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-c.queue.ready:
+			for {
+				f, ok := c.queue.pop()
+				if !ok {
+					break
+				}
+				if c.replayed(f) {
+					continue
+				}
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := c.conn.WriteMessage(websocket.TextMessage, f.Data); err != nil {
+					c.close()
+					return
+				}
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if c.slow.Load() {
+				message = websocket.FormatCloseMessage(CloseSlowConsumer, "slow consumer, reconnect with last_seq")
+			}
+			if c.revoked.Load() {
+				message = websocket.FormatCloseMessage(CloseAccountDeleted, "account deleted")
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, message)
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package realtime
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// frame adalah event yang sudah di-encode. Seq terisi jika event tersimpan di event log
+// (bisa dipakai client untuk resume), 0 untuk event sementara seperti typing.
+type frame struct {
+	Seq  uint64
+	Data []byte
+}
+
+// connection adalah state yang sama untuk semua transport realtime (WebSocket, SSE):
+// identitas device dan antrian kirim. Hub hanya mengenal connection, sehingga routing
+// event sama persis apa pun transport yang dipakai client.
+type connection struct {
+	UserID      uint
+	DeviceID    string
+	Transport   string // "websocket" atau "sse"
+	ConnectedAt time.Time
+
+	queue     *sendQueue
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// slow diisi jika koneksi diputus karena tidak sanggup mengikuti laju event
+	slow atomic.Bool
+	// revoked diisi jika koneksi diputus karena akun user dihapus
+	revoked atomic.Bool
+
+	// replayedUntil adalah seq terakhir yang sudah dikirim saat handshake. Event dengan seq
+	// tidak lebih besar yang ikut masuk antrian selama handshake tidak dikirim dua kali.
+	replayedUntil uint64
+}
+
+func newConnection(userID uint, deviceID, transport string, queueSize int, slowGrace time.Duration) *connection {
+	return &connection{
+		UserID:      userID,
+		DeviceID:    deviceID,
+		Transport:   transport,
+		ConnectedAt: time.Now().UTC(),
+		queue:       newSendQueue(queueSize, slowGrace),
+		done:        make(chan struct{}),
+	}
+}
+
+// enqueue memasukkan frame ke antrian kirim tanpa pernah memblokir pengirim.
+// Koneksi yang antriannya tetap penuh setelah event typing/presence digabung, lebih lama
+// dari masa tenggang, dianggap terlalu lambat dan diputus. Client bisa reconnect dengan last_seq untuk melanjutkan.
+func (c *connection) enqueue(f frame) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	if !c.queue.push(f) {
+		log.Printf("Realtime client user=%d device=%s is too slow, closing", c.UserID, c.DeviceID)
+		c.slow.Store(true)
+		c.close()
+	}
+}
+
+func (c *connection) sendEvent(event models.Event) {
+	c.enqueue(encodeFrame(event))
+}
+
+// handshake menyiapkan frame pertama untuk koneksi baru: hello, lalu event yang terlewat
+// sejak lastSeq jika client meminta resume, atau sinyal resync jika event log tidak lagi
+// menyimpan semuanya. Koneksi harus sudah terdaftar di hub agar tidak ada event yang jatuh di antaranya.
+func (c *connection) handshake(events *EventLog, pingInterval time.Duration, lastSeq *uint64) []frame {
+	var (
+		missed []frame
+		latest uint64
+		err    error
+	)
+	if lastSeq != nil {
+		missed, latest, err = events.Since(c.UserID, *lastSeq)
+		c.replayedUntil = latest
+	} else {
+		latest, err = events.Latest(c.UserID)
+	}
+	if err != nil && err != ErrResyncRequired {
+		log.Printf("Failed to read realtime event log of user %d: %v", c.UserID, err)
+	}
+
+	frames := []frame{encodeFrame(models.NewEvent(models.EventHello, models.HelloEvent{
+		UserID:       c.UserID,
+		DeviceID:     c.DeviceID,
+		PingInterval: int(pingInterval / time.Second),
+		Seq:          latest,
+	}))}
+	if lastSeq != nil && err != nil {
+		// event log tidak lengkap atau tidak bisa dibaca, client harus memuat ulang state
+		return append(frames, encodeFrame(models.NewEvent(models.EventResync, models.ResyncEvent{Seq: latest})))
+	}
+	return append(frames, missed...)
+}
+
+// replayed mengecek apakah frame sudah terkirim saat handshake
+func (c *connection) replayed(f frame) bool {
+	return f.Seq != 0 && f.Seq <= c.replayedUntil
+}
+
+func encodeFrame(event models.Event) frame {
+	data, _ := json.Marshal(event)
+	return frame{Seq: event.Seq, Data: data}
+}
+
+func (c *connection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
@@ -0,0 +1,177 @@
+package realtime
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// eventStreamTTL menghapus event log user yang sudah lama tidak menerima event.
+// Counter seq sengaja tidak diberi TTL agar seq user tidak pernah mundur.
+const eventStreamTTL = 24 * time.Hour
+
+// ErrResyncRequired berarti sebagian event yang terlewat sudah terbuang dari event log
+var ErrResyncRequired = errors.New("missed events are no longer retained")
+
+// transientEvents tidak disimpan di event log karena tidak berguna lagi setelah terlewat:
+// typing dan presence cukup state terakhirnya, signal WebRTC sudah basi saat reconnect
+var transientEvents = map[string]bool{
+	models.EventTyping:     true,
+	models.EventPresence:   true,
+	models.EventCallSignal: true,
+	models.EventRoomSignal: true,
+}
+
+// appendScript menaikkan seq user dan menyimpan event dengan ID stream "<seq>-0" secara atomik,
+// sehingga urutan di stream selalu sama dengan urutan seq walaupun dipublish dari banyak instance.
+// Jika counter hilang tapi stream masih ada, seq dilanjutkan dari event terakhir di stream.
+var appendScript = redis.NewScript(`
+local seq = redis.call("INCR", KEYS[1])
+local last = redis.call("XREVRANGE", KEYS[2], "+", "-", "COUNT", 1)[1]
+if last then
+	local lastSeq = tonumber(string.match(last[1], "^(%d+)"))
+	if lastSeq >= seq then
+		seq = lastSeq + 1
+		redis.call("SET", KEYS[1], seq)
+	end
+end
+redis.call("XADD", KEYS[2], "MAXLEN", "~", ARGV[1], seq .. "-0", "event", ARGV[2])
+redis.call("EXPIRE", KEYS[2], ARGV[3])
+return seq
+`)
+
+func eventSeqKey(userID uint) string {
+	return fmt.Sprintf("realtime:seq:%d", userID)
+}
+
+func eventStreamKey(userID uint) string {
+	return fmt.Sprintf("realtime:events:%d", userID)
+}
+
+// loggedEvent dipakai untuk membaca ulang event dari log tanpa mengubah bentuk payload-nya
+type loggedEvent struct {
+	models.Event
+	Data json.RawMessage `json:"data"`
+}
+
+// EventLog memberi setiap event user nomor urut (seq) dan menyimpan event terbaru
+// di Redis Stream dengan panjang terbatas, sehingga client yang reconnect bisa
+// meminta event yang terlewat.
+type EventLog struct {
+	redisClient *redis.Client
+	maxLen      int64
+}
+
+func NewEventLog(redisClient *redis.Client, maxLen int64) *EventLog {
+	return &EventLog{redisClient: redisClient, maxLen: maxLen}
+}
+
+// Append menyimpan event ke log setiap penerima dan mengembalikan frame per penerima
+// (urutan sama dengan userIDs). Setiap frame membawa seq milik penerimanya.
+func (l *EventLog) Append(userIDs []uint, event models.Event) ([]frame, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]frame, len(userIDs))
+	if transientEvents[event.Type] {
+		for i := range frames {
+			frames[i] = frame{Data: data}
+		}
+		return frames, nil
+	}
+
+	ctx := context.Background()
+	pipe := l.redisClient.Pipeline()
+	cmds := make([]*redis.Cmd, len(userIDs))
+	for i, userID := range userIDs {
+		keys := []string{eventSeqKey(userID), eventStreamKey(userID)}
+		cmds[i] = appendScript.Eval(ctx, pipe, keys, l.maxLen, data, int(eventStreamTTL/time.Second))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		seq, err := cmd.Int64()
+		if err != nil {
+			return nil, err
+		}
+		event.Seq = uint64(seq)
+		if frames[i].Data, err = json.Marshal(event); err != nil {
+			return nil, err
+		}
+		frames[i].Seq = event.Seq
+	}
+	return frames, nil
+}
+
+// Latest mengambil seq terakhir user (0 jika user belum pernah menerima event)
+func (l *EventLog) Latest(userID uint) (uint64, error) {
+	seq, err := l.redisClient.Get(context.Background(), eventSeqKey(userID)).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// Since mengambil event user dengan seq setelah lastSeq (dari yang terlama) beserta seq terakhir user.
+// Mengembalikan ErrResyncRequired jika ada event di antaranya yang sudah tidak tersimpan.
+func (l *EventLog) Since(userID uint, lastSeq uint64) ([]frame, uint64, error) {
+	ctx := context.Background()
+	pipe := l.redisClient.TxPipeline()
+	latestCmd := pipe.Get(ctx, eventSeqKey(userID))
+	rangeCmd := pipe.XRange(ctx, eventStreamKey(userID), fmt.Sprintf("%d-0", lastSeq+1), "+")
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, 0, err
+	}
+
+	latest, err := latestCmd.Uint64()
+	if err != nil && err != redis.Nil {
+		return nil, 0, err
+	}
+	if lastSeq >= latest {
+		if lastSeq > latest {
+			// client memegang seq yang tidak pernah dikeluarkan server
+			return nil, latest, ErrResyncRequired
+		}
+		return nil, latest, nil
+	}
+
+	entries := rangeCmd.Val()
+	if len(entries) == 0 || entrySeq(entries[0].ID) != lastSeq+1 {
+		return nil, latest, ErrResyncRequired
+	}
+
+	frames := make([]frame, 0, len(entries))
+	for _, entry := range entries {
+		raw, _ := entry.Values["event"].(string)
+
+		event := loggedEvent{}
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		event.Seq = entrySeq(entry.ID)
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		frames = append(frames, frame{Seq: event.Seq, Data: data})
+	}
+	return frames, latest, nil
+}
+
+// entrySeq mengambil seq dari ID stream "<seq>-0"
+func entrySeq(id string) uint64 {
+	seq, _, _ := strings.Cut(id, "-")
+	value, _ := strconv.ParseUint(seq, 10, 64)
+	return value
+}
@@ -0,0 +1,233 @@
+package realtime
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handler memproses event yang dikirim client lewat koneksi realtime
+type Handler func(userID uint, deviceID string, data json.RawMessage) error
+
+// Hub menyimpan semua koneksi realtime (WebSocket dan SSE) di instance ini, dikelompokkan per user dan device.
+// Event dari usecase dikirim lewat Broker, Hub hanya mengantar ke koneksi lokal.
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[uint]map[string]*connection
+	handlers map[string]Handler
+
+	onConnect    []func(userID uint) // koneksi pertama user di instance ini dibuka
+	onDisconnect []func(userID uint) // koneksi terakhir user di instance ini ditutup
+
+	onDeviceConnect    []func(userID uint, deviceID string)
+	onDeviceDisconnect []func(userID uint, deviceID string)
+	onDeviceAlive      []func(userID uint, deviceID string, active bool)
+
+	queueSize       int           // kapasitas antrian kirim setiap koneksi
+	slowGrace       time.Duration // berapa lama antrian boleh melewati kapasitas sebelum koneksi diputus
+	slowDisconnects atomic.Uint64
+}
+
+func NewHub(queueSize int, slowGrace time.Duration) *Hub {
+	return &Hub{
+		clients:   map[uint]map[string]*connection{},
+		handlers:  map[string]Handler{},
+		queueSize: queueSize,
+		slowGrace: slowGrace,
+	}
+}
+
+// Handle mendaftarkan handler untuk tipe event inbound dari client
+func (h *Hub) Handle(eventType string, handler Handler) {
+	h.handlers[eventType] = handler
+}
+
+// OnUser mendaftarkan callback saat user mulai/berhenti punya koneksi aktif di instance ini
+func (h *Hub) OnUser(onConnect, onDisconnect func(userID uint)) {
+	h.onConnect = append(h.onConnect, onConnect)
+	h.onDisconnect = append(h.onDisconnect, onDisconnect)
+}
+
+// OnDevice mendaftarkan callback saat koneksi sebuah device dibuka/ditutup.
+// Koneksi yang digantikan koneksi baru dari device yang sama tidak dianggap ditutup.
+// Callback yang nil dilewati.
+func (h *Hub) OnDevice(onConnect, onDisconnect func(userID uint, deviceID string)) {
+	if onConnect != nil {
+		h.onDeviceConnect = append(h.onDeviceConnect, onConnect)
+	}
+	if onDisconnect != nil {
+		h.onDeviceDisconnect = append(h.onDeviceDisconnect, onDisconnect)
+	}
+}
+
+// OnDeviceAlive mendaftarkan callback saat koneksi device terbukti masih hidup (pong atau
+// frame dari client). active berarti frame berasal dari interaksi user, bukan keepalive.
+func (h *Hub) OnDeviceAlive(callback func(userID uint, deviceID string, active bool)) {
+	h.onDeviceAlive = append(h.onDeviceAlive, callback)
+}
+
+func (h *Hub) alive(client *connection, active bool) {
+	for _, callback := range h.onDeviceAlive {
+		callback(client.UserID, client.DeviceID, active)
+	}
+}
+
+// register menyimpan koneksi, koneksi lama dengan device yang sama akan ditutup
+func (h *Hub) register(client *connection) {
+	h.mu.Lock()
+	devices, ok := h.clients[client.UserID]
+	if !ok {
+		devices = map[string]*connection{}
+		h.clients[client.UserID] = devices
+	}
+	previous := devices[client.DeviceID]
+	devices[client.DeviceID] = client
+	first := len(devices) == 1 && previous == nil
+	h.mu.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+	if first {
+		for _, callback := range h.onConnect {
+			callback(client.UserID)
+		}
+	}
+	for _, callback := range h.onDeviceConnect {
+		callback(client.UserID, client.DeviceID)
+	}
+}
+
+func (h *Hub) unregister(client *connection) {
+	if client.slow.Load() {
+		h.slowDisconnects.Add(1)
+	}
+
+	h.mu.Lock()
+	devices := h.clients[client.UserID]
+	if devices[client.DeviceID] != client {
+		// sudah digantikan koneksi baru dari device yang sama
+		h.mu.Unlock()
+		return
+	}
+	delete(devices, client.DeviceID)
+	last := len(devices) == 0
+	if last {
+		delete(h.clients, client.UserID)
+	}
+	h.mu.Unlock()
+
+	for _, callback := range h.onDeviceDisconnect {
+		callback(client.UserID, client.DeviceID)
+	}
+	if last {
+		for _, callback := range h.onDisconnect {
+			callback(client.UserID)
+		}
+	}
+}
+
+// deliver mengirim event yang sudah di-encode ke semua device user yang terhubung ke instance ini
+func (h *Hub) deliver(userID uint, f frame) {
+	h.mu.RLock()
+	targets := make([]*connection, 0, len(h.clients[userID]))
+	for _, client := range h.clients[userID] {
+		targets = append(targets, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range targets {
+		client.enqueue(f)
+	}
+}
+
+// closeUser menutup semua koneksi user di instance ini dengan close code CloseAccountDeleted
+func (h *Hub) closeUser(userID uint) {
+	h.mu.RLock()
+	targets := make([]*connection, 0, len(h.clients[userID]))
+	for _, client := range h.clients[userID] {
+		targets = append(targets, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range targets {
+		client.revoked.Store(true)
+		client.close()
+	}
+}
+
+// ConnectionStats adalah metrik satu koneksi realtime
+type ConnectionStats struct {
+	UserID      uint       `json:"user_id"`
+	DeviceID    string     `json:"device_id"`
+	Transport   string     `json:"transport"`
+	ConnectedAt time.Time  `json:"connected_at"`
+	Queue       QueueStats `json:"queue"`
+}
+
+// HubStats adalah metrik semua koneksi realtime di instance ini
+type HubStats struct {
+	Connections     int               `json:"connections"`
+	QueueCapacity   int               `json:"queue_capacity"`
+	SlowDisconnects uint64            `json:"slow_disconnects"` // total sejak instance berjalan
+	Clients         []ConnectionStats `json:"clients"`
+}
+
+// Stats mengambil metrik antrian kirim setiap koneksi, diurutkan dari antrian terpanjang
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	targets := []*connection{}
+	for _, devices := range h.clients {
+		for _, client := range devices {
+			targets = append(targets, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	stats := HubStats{
+		Connections:     len(targets),
+		QueueCapacity:   h.queueSize,
+		SlowDisconnects: h.slowDisconnects.Load(),
+		Clients:         make([]ConnectionStats, 0, len(targets)),
+	}
+	for _, client := range targets {
+		stats.Clients = append(stats.Clients, ConnectionStats{
+			UserID:      client.UserID,
+			DeviceID:    client.DeviceID,
+			Transport:   client.Transport,
+			ConnectedAt: client.ConnectedAt,
+			Queue:       client.queue.snapshot(),
+		})
+	}
+	sort.Slice(stats.Clients, func(i, j int) bool {
+		return stats.Clients[i].Queue.Length > stats.Clients[j].Queue.Length
+	})
+	return stats
+}
+
+// IsConnected mengecek apakah user punya koneksi aktif di instance ini
+func (h *Hub) IsConnected(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID]) > 0
+}
+
+// dispatch meneruskan event inbound ke handler yang terdaftar
+func (h *Hub) dispatch(client *connection, inbound models.InboundEvent) {
+	if inbound.Type == "ping" {
+		client.sendEvent(models.NewEvent(models.EventPong, map[string]string{"ref": inbound.Ref}))
+		return
+	}
+
+	handler, ok := h.handlers[inbound.Type]
+	if !ok {
+		client.sendEvent(models.NewEvent(models.EventError, models.ErrorEvent{Ref: inbound.Ref, Message: "unknown event type: " + inbound.Type}))
+		return
+	}
+	if err := handler(client.UserID, client.DeviceID, inbound.Data); err != nil {
+		client.sendEvent(models.NewEvent(models.EventError, models.ErrorEvent{Ref: inbound.Ref, Message: err.Error()}))
+	}
+}
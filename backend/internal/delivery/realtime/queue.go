@@ -0,0 +1,179 @@
+package realtime
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// overflowFactor membatasi panjang antrian selama masa tenggang, relatif terhadap kapasitasnya
+const overflowFactor = 2
+
+// coalescibleEvents hanya membawa state terakhir. Saat antrian penuh, frame lama yang sudah
+// tertimpa frame lebih baru dengan key yang sama dibuang.
+var coalescibleEvents = map[string]bool{
+	models.EventTyping:   true,
+	models.EventPresence: true,
+}
+
+// coalesceKey menentukan state yang dibawa frame (misalnya typing user X di conversation Y),
+// kosong jika frame tidak boleh digabung. Event dengan seq tidak pernah digabung.
+func coalesceKey(f frame) string {
+	if f.Seq != 0 {
+		return ""
+	}
+
+	var meta struct {
+		Type string `json:"type"`
+		Data struct {
+			ConversationID string `json:"conversation_id"`
+			UserID         uint   `json:"user_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(f.Data, &meta); err != nil || !coalescibleEvents[meta.Type] {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%d", meta.Type, meta.Data.ConversationID, meta.Data.UserID)
+}
+
+// QueueStats adalah metrik antrian kirim satu koneksi
+type QueueStats struct {
+	Length    int    `json:"length"`
+	Capacity  int    `json:"capacity"`
+	HighWater int    `json:"high_water"` // panjang antrian terbesar yang pernah tercapai
+	Enqueued  uint64 `json:"enqueued"`
+	Sent      uint64 `json:"sent"`
+	Coalesced uint64 `json:"coalesced"` // frame typing/presence yang dibuang karena tertimpa
+}
+
+type queuedFrame struct {
+	frame
+	key string
+}
+
+// sendQueue adalah antrian kirim berbatas milik satu koneksi. Pengirim tidak pernah menunggu.
+// Antrian yang tetap penuh setelah frame yang tertimpa dibuang masih boleh melewati
+// kapasitasnya selama masa tenggang (sampai overflowFactor kali kapasitas), agar lonjakan
+// sesaat tidak memutus koneksi. push baru gagal jika antrian melewati kapasitas lebih lama
+// dari masa tenggang atau batas tersebut.
+type sendQueue struct {
+	mu     sync.Mutex
+	frames []queuedFrame // frame yang belum terkirim adalah frames[head:]
+	head   int
+	stats  QueueStats
+
+	grace     time.Duration
+	overSince time.Time // sejak kapan antrian melewati kapasitas, nol jika tidak
+
+	// ready diisi setiap ada frame baru, writer cukup menunggu channel ini
+	ready chan struct{}
+}
+
+func newSendQueue(capacity int, grace time.Duration) *sendQueue {
+	return &sendQueue{
+		frames: make([]queuedFrame, 0, capacity),
+		stats:  QueueStats{Capacity: capacity},
+		grace:  grace,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+func (q *sendQueue) push(f frame) bool {
+	item := queuedFrame{frame: f, key: coalesceKey(f)}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.length() >= q.stats.Capacity {
+		q.coalesce(item.key)
+	}
+	if q.length() >= q.stats.Capacity {
+		now := time.Now()
+		if q.overSince.IsZero() {
+			q.overSince = now
+		}
+		if now.Sub(q.overSince) > q.grace || q.length() >= q.stats.Capacity*overflowFactor {
+			return false
+		}
+	}
+
+	q.frames = append(q.frames, item)
+	q.stats.Enqueued++
+	if q.length() > q.stats.HighWater {
+		q.stats.HighWater = q.length()
+	}
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (q *sendQueue) length() int {
+	return len(q.frames) - q.head
+}
+
+// coalesce membuang frame yang sudah tertimpa frame setelahnya, termasuk frame baru dengan
+// key incoming yang belum masuk antrian
+func (q *sendQueue) coalesce(incoming string) {
+	seen := map[string]bool{}
+	if incoming != "" {
+		seen[incoming] = true
+	}
+
+	pending := q.frames[q.head:]
+	kept := len(pending)
+	for i := len(pending) - 1; i >= 0; i-- {
+		item := pending[i]
+		if item.key != "" && seen[item.key] {
+			continue
+		}
+		if item.key != "" {
+			seen[item.key] = true
+		}
+		kept--
+		pending[kept] = item
+	}
+
+	q.stats.Coalesced += uint64(kept)
+	total := len(q.frames)
+	q.frames = append(q.frames[:0], pending[kept:]...)
+	clear(q.frames[len(q.frames):total])
+	q.head = 0
+}
+
+// pop mengambil frame terlama, false jika antrian kosong
+func (q *sendQueue) pop() (frame, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.length() == 0 {
+		return frame{}, false
+	}
+	item := q.frames[q.head]
+	q.frames[q.head] = queuedFrame{}
+	q.head++
+	q.stats.Sent++
+	if q.length() < q.stats.Capacity {
+		q.overSince = time.Time{}
+	}
+
+	// buffer dipakai ulang dari awal setiap kali antrian kosong
+	if q.head == len(q.frames) {
+		q.frames = q.frames[:0]
+		q.head = 0
+	}
+	return item.frame, true
+}
+
+func (q *sendQueue) snapshot() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := q.stats
+	stats.Length = q.length()
+	return stats
+}
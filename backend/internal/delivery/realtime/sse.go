@@ -0,0 +1,103 @@
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// sseHeartbeatPeriod dibuat lebih pendek dari idle timeout proxy pada umumnya
+	sseHeartbeatPeriod = 25 * time.Second
+	sseRetry           = 3 * time.Second
+)
+
+// sseStream menulis event ke response HTTP dalam format text/event-stream
+type sseStream struct {
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	controller *http.ResponseController
+}
+
+func (s *sseStream) write(format string, args ...interface{}) error {
+	s.controller.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := fmt.Fprintf(s.w, format, args...); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// writeFrame mengirim satu event. JSON dari encoding/json tidak pernah mengandung
+// newline, jadi satu baris data sudah cukup.
+func (s *sseStream) writeFrame(f frame) error {
+	if f.Seq != 0 {
+		return s.write("id: %d\ndata: %s\n\n", f.Seq, f.Data)
+	}
+	return s.write("data: %s\n\n", f.Data)
+}
+
+// ServeSSE mengalirkan event realtime sebagai Server-Sent Events sampai client memutus koneksi.
+// Event yang dikirim sama persis dengan WebSocket; event dari client (typing, read)
+// dikirim lewat REST API. Jika lastSeq diisi, event yang terlewat dikirim ulang dulu.
+func ServeSSE(hub *Hub, events *EventLog, w http.ResponseWriter, r *http.Request, userID uint, deviceID string, lastSeq *uint64) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming is not supported")
+	}
+	stream := &sseStream{w: w, flusher: flusher, controller: http.NewResponseController(w)}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // matikan buffering response di nginx
+	w.WriteHeader(http.StatusOK)
+
+	conn := newConnection(userID, deviceID, "sse", hub.queueSize, hub.slowGrace)
+	hub.register(conn)
+	defer func() {
+		hub.unregister(conn)
+		conn.close()
+	}()
+
+	if err := stream.write("retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return nil
+	}
+	for _, f := range conn.handshake(events, sseHeartbeatPeriod, lastSeq) {
+		if err := stream.writeFrame(f); err != nil {
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(sseHeartbeatPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.queue.ready:
+			for {
+				f, ok := conn.queue.pop()
+				if !ok {
+					break
+				}
+				if conn.replayed(f) {
+					continue
+				}
+				if err := stream.writeFrame(f); err != nil {
+					return nil
+				}
+			}
+		case <-ticker.C:
+			if err := stream.write(": ping\n\n"); err != nil {
+				return nil
+			}
+		case <-conn.done:
+			// koneksi yang terlalu lambat cukup ditutup, EventSource reconnect dengan Last-Event-ID
+			return nil
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupCallRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.CallController, mysqlDB *gorm.DB) {
+	callGroup := router.Group("/calls")
+	callGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		callGroup.POST("", ctrl.StartCall)
+		callGroup.GET("/:id", ctrl.GetCall)
+		callGroup.POST("/:id/accept", ctrl.AcceptCall)
+		callGroup.POST("/:id/decline", ctrl.DeclineCall)
+		callGroup.POST("/:id/end", ctrl.EndCall)
+		callGroup.POST("/:id/signal", ctrl.Signal)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupContactRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.ContactController, mysqlDB *gorm.DB) {
+	contactGroup := router.Group("/contacts")
+	contactGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		contactGroup.GET("/discovery", ctrl.GetDiscoveryConfig)
+		contactGroup.POST("/discover", ctrl.Discover)
+		contactGroup.PUT("/discoverable", ctrl.SetDiscoverable)
+	}
+}
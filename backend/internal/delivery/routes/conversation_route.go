@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupConversationRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.ConversationController, mysqlDB *gorm.DB) {
+	conversationGroup := router.Group("/conversations")
+	conversationGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		conversationGroup.GET("/unread/badge", ctrl.UnreadBadge)
+		conversationGroup.POST("/dm/:user_id", ctrl.GetOrCreateDM)
+		conversationGroup.GET("/:id/unread", ctrl.GetUnreadCount)
+		conversationGroup.POST("/:id/read", ctrl.MarkAsRead)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupExportRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.ExportController, mysqlDB *gorm.DB) {
+	// link download bersifat publik tapi dibatasi token yang cepat kedaluwarsa
+	router.GET("/exports/download/:token", ctrl.Download)
+
+	exportGroup := router.Group("")
+	exportGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		exportGroup.POST("/conversations/:id/exports", ctrl.CreateExport)
+		exportGroup.GET("/exports/:job_id", ctrl.GetExport)
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupFriendRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.FriendController, mysqlDB *gorm.DB) {
+	friendGroup := router.Group("/friends")
+	friendGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		friendGroup.GET("", ctrl.GetFriends)
+		friendGroup.GET("/suggestions", ctrl.GetSuggestions)
+		friendGroup.DELETE("/:user_id", ctrl.Unfriend)
+		friendGroup.GET("/requests/incoming", ctrl.GetIncomingRequests)
+		friendGroup.GET("/requests/outgoing", ctrl.GetOutgoingRequests)
+		friendGroup.POST("/requests", ctrl.SendRequest)
+		friendGroup.POST("/requests/:id/accept", ctrl.AcceptRequest)
+		friendGroup.POST("/requests/:id/decline", ctrl.DeclineRequest)
+		friendGroup.DELETE("/requests/:id", ctrl.CancelRequest)
+		friendGroup.GET("/blocks", ctrl.GetBlocked)
+		friendGroup.POST("/blocks", ctrl.Block)
+		friendGroup.DELETE("/blocks/:user_id", ctrl.Unblock)
+	}
+}
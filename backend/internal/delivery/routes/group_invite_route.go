@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupGroupInviteRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.GroupInviteController, mysqlDB *gorm.DB) {
+	manageGroup := router.Group("/groups/:id/invites")
+	manageGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		manageGroup.GET("", ctrl.GetInvites)
+		manageGroup.POST("", ctrl.CreateInvite)
+		manageGroup.DELETE("/:invite_id", ctrl.RevokeInvite)
+		manageGroup.GET("/:invite_id/uses", ctrl.GetInviteUses)
+	}
+
+	inviteGroup := router.Group("/invites")
+	inviteGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		inviteGroup.GET("/:token", ctrl.PreviewInvite)
+		inviteGroup.POST("/:token/join", ctrl.JoinInvite)
+	}
+}
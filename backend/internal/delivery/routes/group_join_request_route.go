@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupGroupJoinRequestRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.GroupJoinRequestController, mysqlDB *gorm.DB) {
+	requestGroup := router.Group("/groups/:id")
+	requestGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		requestGroup.POST("/join", ctrl.JoinGroup)
+		requestGroup.GET("/join-requests", ctrl.GetJoinRequests)
+		requestGroup.POST("/join-requests/:request_id/approve", ctrl.ApproveJoinRequest)
+		requestGroup.POST("/join-requests/:request_id/reject", ctrl.RejectJoinRequest)
+	}
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupGroupRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.GroupController, mysqlDB *gorm.DB) {
+	groupGroup := router.Group("/groups")
+	groupGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		groupGroup.GET("", ctrl.GetGroups)
+		groupGroup.POST("", ctrl.CreateGroup)
+		groupGroup.GET("/:id", ctrl.GetGroup)
+		groupGroup.GET("/:id/preview", ctrl.PreviewGroup)
+		groupGroup.PATCH("/:id", ctrl.UpdateGroup)
+		groupGroup.DELETE("/:id", ctrl.DeleteGroup)
+		groupGroup.POST("/:id/members", ctrl.AddMembers)
+		groupGroup.PATCH("/:id/members/:user_id", ctrl.UpdateMemberRole)
+		groupGroup.DELETE("/:id/members/:user_id", ctrl.RemoveMember)
+		groupGroup.POST("/:id/leave", ctrl.LeaveGroup)
+		groupGroup.POST("/:id/transfer", ctrl.TransferOwnership)
+		groupGroup.GET("/:id/permissions", ctrl.GetPermissions)
+		groupGroup.GET("/:id/roles", ctrl.GetRoles)
+		groupGroup.PATCH("/:id/roles/:role", ctrl.UpdateRole)
+	}
+}
@@ -1 +1,27 @@
-package routes
\ No newline at end of file
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupMessageRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.MessageController, mysqlDB *gorm.DB) {
+	messageGroup := router.Group("/messages")
+	messageGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		messageGroup.GET("/search", ctrl.Search)
+		messageGroup.POST("", ctrl.Send)
+		messageGroup.PATCH("/:id", ctrl.Edit)
+		messageGroup.DELETE("/:id", ctrl.Delete)
+	}
+
+	conversationGroup := router.Group("/conversations")
+	conversationGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		conversationGroup.GET("/:id/messages", ctrl.ListByConversation)
+	}
+}
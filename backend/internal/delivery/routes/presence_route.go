@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupPresenceRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.PresenceController, mysqlDB *gorm.DB) {
+	presenceGroup := router.Group("/presence")
+	presenceGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		presenceGroup.POST("/heartbeat", ctrl.Heartbeat)
+		presenceGroup.GET("/friends", ctrl.GetFriends)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupPrivacyRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.PrivacyController, mysqlDB *gorm.DB) {
+	privacyGroup := router.Group("/privacy")
+	privacyGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		privacyGroup.GET("", ctrl.GetSettings)
+		privacyGroup.PATCH("", ctrl.UpdateSettings)
+	}
+}
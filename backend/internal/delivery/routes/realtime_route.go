@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+	"echo-chat-app-backend/internal/usecases"
+	"os"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupRealtimeRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.RealtimeController, ticketUseCase *usecases.RealtimeTicketUseCase, mysqlDB *gorm.DB) {
+	router.POST("/realtime/ticket", middlewares.AuthMiddleware(mysqlDB, authClient), ctrl.IssueTicket)
+	router.GET("/ws", middlewares.WSAuthMiddleware(mysqlDB, authClient, ticketUseCase), ctrl.ServeWS)
+	router.GET("/events", middlewares.WSAuthMiddleware(mysqlDB, authClient, ticketUseCase), ctrl.ServeSSE)
+
+	// metrik per instance untuk operator, bukan untuk client
+	router.GET("/realtime/metrics", middlewares.MetricsMiddleware(os.Getenv("REALTIME_METRICS_TOKEN")), ctrl.Metrics)
+}
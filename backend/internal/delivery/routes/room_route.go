@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupRoomRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.RoomController, mysqlDB *gorm.DB) {
+	roomGroup := router.Group("/groups/:id/room")
+	roomGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		roomGroup.GET("", ctrl.GetRoom)
+		roomGroup.POST("", ctrl.CreateRoom)
+		roomGroup.POST("/join", ctrl.JoinRoom)
+		roomGroup.POST("/leave", ctrl.LeaveRoom)
+		roomGroup.PATCH("/state", ctrl.UpdateState)
+		roomGroup.POST("/signal", ctrl.Signal)
+	}
+}
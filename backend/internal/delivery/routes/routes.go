@@ -1,34 +1,171 @@
 package routes
 
 import (
+	"echo-chat-app-backend/config"
 	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+	"echo-chat-app-backend/internal/delivery/realtime"
 	"echo-chat-app-backend/internal/repositories"
 	"echo-chat-app-backend/internal/usecases"
+	"log"
+	"os"
+	"time"
 
 	"firebase.google.com/go/v4/auth"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 )
 
-func SetupRouter(mysqlDB *gorm.DB, firebaseAuth *auth.Client) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(mysqlDB *gorm.DB, mongoDB *mongo.Database, redisClient *redis.Client, firebaseAuth *auth.Client) *gin.Engine {
+	router := gin.New()
+	router.Use(middlewares.Logger(), gin.Recovery())
 	router.Use(cors.Default())
 
 	// declare repositories, usecases, controllers here
 	// Dependency Injection
 	authRepo := repositories.NewAuthRepository(mysqlDB)
-    authUseCase := usecases.NewAuthUseCase(authRepo)
+	contactSalt := os.Getenv("CONTACT_DISCOVERY_SALT")
+    authUseCase := usecases.NewAuthUseCase(authRepo, contactSalt)
 	authController := controllers.NewAuthController(authUseCase)
 
 	userRepo := repositories.NewUserRepository(firebaseAuth, mysqlDB)
-	userUseCase := usecases.NewUserUseCase(userRepo)
+	friendshipRepo := repositories.NewFriendshipRepository(mysqlDB)
+
+	// Realtime: hub menyimpan koneksi lokal, broker menyebarkan event antar instance lewat Redis
+	hub := realtime.NewHub(config.GetInt("REALTIME_SEND_QUEUE_SIZE", 256), config.GetDuration("REALTIME_SLOW_CONSUMER_GRACE", 10*time.Second))
+	events := realtime.NewEventLog(redisClient, int64(config.GetInt("REALTIME_EVENT_LOG_SIZE", 500)))
+	broker := realtime.NewBroker(hub, events, redisClient)
+	go broker.Run()
+
+	privacyRepo := repositories.NewPrivacyRepository(mysqlDB)
+	presenceRepo := repositories.NewPresenceRepository(redisClient)
+	presenceUseCase := usecases.NewPresenceUseCase(presenceRepo, userRepo, friendshipRepo, privacyRepo, broker, usecases.PresenceConfig{
+		HeartbeatTTL: config.GetDuration("PRESENCE_HEARTBEAT_TTL", 90*time.Second),
+		AwayAfter:    config.GetDuration("PRESENCE_AWAY_AFTER", 5*time.Minute),
+	})
+	hub.OnDevice(presenceUseCase.DeviceConnected, presenceUseCase.DeviceDisconnected)
+	hub.OnDeviceAlive(presenceUseCase.DeviceAlive)
+	presenceController := controllers.NewPresenceController(presenceUseCase)
+
+	privacyUseCase := usecases.NewPrivacyUseCase(privacyRepo, friendshipRepo, presenceUseCase)
+	privacyController := controllers.NewPrivacyController(privacyUseCase)
+
+	contactUseCase := usecases.NewContactUseCase(userRepo, friendshipRepo, privacyUseCase, usecases.ContactConfig{
+		Salt:       contactSalt,
+		MaxBatch:   config.GetInt("CONTACT_DISCOVERY_MAX_BATCH", 500),
+		RateLimit:  int64(config.GetInt("CONTACT_DISCOVERY_RATE", 20)),
+		RateWindow: config.GetDuration("CONTACT_DISCOVERY_WINDOW", 24*time.Hour),
+	})
+	contactController := controllers.NewContactController(contactUseCase)
+
+	conversationRepo := repositories.NewConversationRepository(mongoDB)
+	messageRepo := repositories.NewMessageRepository(mongoDB)
+	groupRepo := repositories.NewGroupRepository(mysqlDB)
+	groupPermissionRepo := repositories.NewGroupPermissionRepository(mysqlDB)
+	groupPermissionUseCase := usecases.NewGroupPermissionUseCase(groupRepo, groupPermissionRepo, broker)
+	unreadUseCase := usecases.NewUnreadUseCase(conversationRepo, messageRepo, groupRepo, groupPermissionUseCase, broker)
+	conversationUseCase := usecases.NewConversationUseCase(conversationRepo, userRepo, friendshipRepo, privacyUseCase)
+	conversationController := controllers.NewConversationController(conversationUseCase, unreadUseCase)
+
+	groupUseCase := usecases.NewGroupUseCase(groupRepo, userRepo, friendshipRepo, privacyUseCase, groupPermissionUseCase, broker)
+	groupController := controllers.NewGroupController(groupUseCase, groupPermissionUseCase)
+
+	userUseCase := usecases.NewUserUseCase(userRepo, friendshipRepo, privacyUseCase, groupUseCase, broker, broker)
 	userController := controllers.NewUserController(userUseCase)
 
+	groupJoinRequestRepo := repositories.NewGroupJoinRequestRepository(mysqlDB)
+	groupJoinRequestUseCase := usecases.NewGroupJoinRequestUseCase(groupJoinRequestRepo, groupRepo, friendshipRepo, groupPermissionUseCase, privacyUseCase, broker, usecases.GroupJoinRequestConfig{
+		TTL: config.GetDuration("GROUP_JOIN_REQUEST_TTL", 7*24*time.Hour),
+	})
+	groupJoinRequestController := controllers.NewGroupJoinRequestController(groupJoinRequestUseCase)
+
+	groupInviteRepo := repositories.NewGroupInviteRepository(mysqlDB)
+	groupInviteUseCase := usecases.NewGroupInviteUseCase(groupInviteRepo, groupRepo, friendshipRepo, groupPermissionUseCase, groupJoinRequestUseCase, privacyUseCase, broker)
+	groupInviteController := controllers.NewGroupInviteController(groupInviteUseCase)
+
+	messageUseCase := usecases.NewMessageUseCase(messageRepo, conversationRepo, groupRepo, userRepo, friendshipRepo, privacyUseCase, groupPermissionUseCase, broker)
+	messageController := controllers.NewMessageController(messageUseCase)
+
+	exportRepo := repositories.NewExportRepository(mongoDB, redisClient)
+	exportUseCase := usecases.NewExportUseCase(exportRepo, messageRepo, conversationRepo, groupRepo, userRepo, groupPermissionUseCase, usecases.ExportConfig{
+		Workers:            config.GetInt("EXPORT_WORKERS", 2),
+		Retention:          config.GetDuration("EXPORT_RETENTION", 24*time.Hour),
+		LinkTTL:            config.GetDuration("EXPORT_LINK_TTL", 15*time.Minute),
+		MaxAttachmentBytes: int64(config.GetInt("EXPORT_MAX_ATTACHMENT_MB", 25)) << 20,
+	})
+	exportController := controllers.NewExportController(exportUseCase)
+
+	typingRepo := repositories.NewTypingRepository(redisClient)
+	typingUseCase := usecases.NewTypingUseCase(typingRepo, conversationRepo, groupRepo, friendshipRepo, groupPermissionUseCase, broker, config.GetDuration("TYPING_TTL", 6*time.Second))
+	typingController := controllers.NewTypingController(typingUseCase)
+
+	suggestionRepo := repositories.NewSuggestionRepository(redisClient)
+	suggestionUseCase := usecases.NewSuggestionUseCase(suggestionRepo, friendshipRepo, groupRepo, userRepo, privacyUseCase, usecases.SuggestionConfig{
+		CacheTTL: config.GetDuration("FRIEND_SUGGESTION_TTL", 6*time.Hour),
+	})
+	friendshipUseCase := usecases.NewFriendshipUseCase(friendshipRepo, suggestionUseCase, broker)
+	friendController := controllers.NewFriendController(friendshipUseCase, suggestionUseCase)
+	syncUseCase := usecases.NewSyncUseCase(messageRepo, conversationRepo, friendshipRepo, groupRepo, userRepo, privacyUseCase)
+	syncController := controllers.NewSyncController(syncUseCase)
+
+	callRepo := repositories.NewCallRepository(redisClient)
+	callUseCase := usecases.NewCallUseCase(callRepo, friendshipRepo, messageUseCase, broker, usecases.CallConfig{
+		RingTimeout: config.GetDuration("CALL_RING_TIMEOUT", 45*time.Second),
+		MaxDuration: config.GetDuration("CALL_MAX_DURATION", 4*time.Hour),
+	})
+	callController := controllers.NewCallController(callUseCase)
+
+	roomRepo := repositories.NewRoomRepository(redisClient)
+	roomUseCase := usecases.NewRoomUseCase(roomRepo, groupRepo, groupPermissionUseCase, messageUseCase, broker, usecases.RoomConfig{
+		MaxParticipants: config.GetInt("CALL_ROOM_MAX_PARTICIPANTS", 8),
+	})
+	hub.OnDevice(nil, roomUseCase.DeviceDisconnected)
+	groupUseCase.OnMember(roomUseCase.MemberLeft, roomUseCase.GroupDeleted)
+	roomController := controllers.NewRoomController(roomUseCase)
+
+	realtimeTicketRepo := repositories.NewRealtimeTicketRepository(redisClient)
+	realtimeTicketUseCase := usecases.NewRealtimeTicketUseCase(realtimeTicketRepo, config.GetDuration("REALTIME_TICKET_TTL", 30*time.Second))
+	realtimeController := controllers.NewRealtimeController(hub, events, typingUseCase, unreadUseCase, presenceUseCase, callUseCase, roomUseCase, realtimeTicketUseCase)
+
+	// MongoDB indexes
+	if err := conversationRepo.EnsureIndexes(); err != nil {
+		log.Printf("Failed to create conversation indexes (run migrations/merge_dm_conversations first if duplicates exist): %v", err)
+	}
+	if err := messageRepo.EnsureIndexes(); err != nil {
+		log.Printf("Failed to create message indexes: %v", err)
+	}
+
+	// Background jobs
+	unreadUseCase.StartReconciler(config.GetDuration("UNREAD_RECONCILE_INTERVAL", 10*time.Minute))
+	exportUseCase.StartWorkers()
+	exportUseCase.StartCleanup(time.Hour)
+	presenceUseCase.StartSweeper(config.GetDuration("PRESENCE_SWEEP_INTERVAL", 15*time.Second))
+	callUseCase.StartTimeouts(time.Second)
+	groupJoinRequestUseCase.StartExpiry(config.GetDuration("GROUP_JOIN_REQUEST_SWEEP_INTERVAL", time.Hour))
+
+	SetupRealtimeRoutes(&router.RouterGroup, firebaseAuth, realtimeController, realtimeTicketUseCase, mysqlDB)
+
 	api := router.Group("/api")
 	{
 		SetupAuthRoutes(api, firebaseAuth, authController, mysqlDB)
 		SetupUserRoutes(api, firebaseAuth, userController, mysqlDB)
+		SetupContactRoutes(api, firebaseAuth, contactController, mysqlDB)
+		SetupPrivacyRoutes(api, firebaseAuth, privacyController, mysqlDB)
+		SetupConversationRoutes(api, firebaseAuth, conversationController, mysqlDB)
+		SetupGroupRoutes(api, firebaseAuth, groupController, mysqlDB)
+		SetupGroupJoinRequestRoutes(api, firebaseAuth, groupJoinRequestController, mysqlDB)
+		SetupGroupInviteRoutes(api, firebaseAuth, groupInviteController, mysqlDB)
+		SetupMessageRoutes(api, firebaseAuth, messageController, mysqlDB)
+		SetupExportRoutes(api, firebaseAuth, exportController, mysqlDB)
+		SetupTypingRoutes(api, firebaseAuth, typingController, mysqlDB)
+		SetupFriendRoutes(api, firebaseAuth, friendController, mysqlDB)
+		SetupPresenceRoutes(api, firebaseAuth, presenceController, mysqlDB)
+		SetupSyncRoutes(api, firebaseAuth, syncController, mysqlDB)
+		SetupCallRoutes(api, firebaseAuth, callController, mysqlDB)
+		SetupRoomRoutes(api, firebaseAuth, roomController, mysqlDB)
 	}
 
 	return router
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupSyncRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.SyncController, mysqlDB *gorm.DB) {
+	router.GET("/sync", middlewares.AuthMiddleware(mysqlDB, authClient), ctrl.Sync)
+}
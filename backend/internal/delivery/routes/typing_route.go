@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"echo-chat-app-backend/internal/delivery/controllers"
+	"echo-chat-app-backend/internal/delivery/middlewares"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupTypingRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *controllers.TypingController, mysqlDB *gorm.DB) {
+	typingGroup := router.Group("/conversations/:id/typing")
+	typingGroup.Use(middlewares.AuthMiddleware(mysqlDB, authClient))
+	{
+		typingGroup.POST("", ctrl.SetTyping)
+		typingGroup.GET("", ctrl.ListTyping)
+	}
+}
@@ -16,5 +16,6 @@ func SetupUserRoutes(router *gin.RouterGroup, authClient *auth.Client, ctrl *con
 		userGroup.GET("/me", ctrl.Me)
 		userGroup.GET("/search", ctrl.SearchUserByUsername)
 		userGroup.PATCH("/me", ctrl.UpdateProfile)
+		userGroup.DELETE("/me", ctrl.DeleteAccount)
 	}
 }
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Call media
+const (
+	CallMediaAudio = "audio"
+	CallMediaVideo = "video"
+)
+
+// Call statuses. Declined, missed and ended are final.
+const (
+	CallStatusRinging  = "ringing"
+	CallStatusAccepted = "accepted"
+	CallStatusDeclined = "declined"
+	CallStatusMissed   = "missed"
+	CallStatusEnded    = "ended"
+)
+
+// WebRTC signal types relayed between call participants
+const (
+	CallSignalOffer  = "offer"
+	CallSignalAnswer = "answer"
+	CallSignalICE    = "ice"
+)
+
+// Call is a 1:1 call session between two friends (stored in Redis while it lasts)
+type Call struct {
+	ID       string `json:"id"`
+	CallerID uint   `json:"caller_id"`
+	CalleeID uint   `json:"callee_id"`
+	Media    string `json:"media"`
+	Status   string `json:"status"`
+
+	// Devices taking part in the call, signals are only meant for these devices.
+	// CalleeDevice is set once the call is accepted.
+	CallerDevice string `json:"caller_device"`
+	CalleeDevice string `json:"callee_device,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	EndedBy    uint       `json:"ended_by,omitempty"` // 0 when the call timed out
+
+	// ExpiresAt is the ring deadline while ringing and the maximum call length once accepted
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Finished reports whether the call reached a final status
+func (c *Call) Finished() bool {
+	return c.Status == CallStatusDeclined || c.Status == CallStatusMissed || c.Status == CallStatusEnded
+}
+
+// Duration is the time between accepting and ending the call, zero if it was never accepted
+func (c *Call) Duration() time.Duration {
+	if c.AcceptedAt == nil || c.EndedAt == nil {
+		return 0
+	}
+	return c.EndedAt.Sub(*c.AcceptedAt)
+}
+
+// CallSummary is attached to the "call" ChatMessage written into the DM when a call finishes,
+// and to the system messages posted when a group room starts and ends
+type CallSummary struct {
+	CallID   string `bson:"call_id" json:"call_id"` // call or room ID
+	Media    string `bson:"media" json:"media"`
+	Status   string `bson:"status" json:"status"`     // "declined", "missed", "ended"; "started" for rooms
+	Duration int64  `bson:"duration" json:"duration"` // seconds
+	Missed   bool   `bson:"missed" json:"missed"`
+}
+
+// CallEvent is the payload of a call event, sent to both participants on every status change
+type CallEvent struct {
+	Call Call `json:"call"`
+}
+
+// CallSignalEvent relays a WebRTC session description or ICE candidate to the other participant.
+// ToDevice is empty while the call is still ringing on every device of the callee.
+type CallSignalEvent struct {
+	CallID     string          `json:"call_id"`
+	FromUserID uint            `json:"from_user_id"`
+	FromDevice string          `json:"from_device"`
+	ToDevice   string          `json:"to_device,omitempty"`
+	Type       string          `json:"type"` // "offer", "answer", "ice"
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Room event actions
+const (
+	RoomActionStarted = "started"
+	RoomActionJoined  = "joined"
+	RoomActionLeft    = "left"
+	RoomActionUpdated = "updated"
+	RoomActionEnded   = "ended"
+)
+
+// CallRoom is a drop-in voice room of a group. It is open while at least one member
+// is in it (stored in Redis).
+type CallRoom struct {
+	ID           string            `json:"id"`
+	GroupID      uint              `json:"group_id"`
+	StartedBy    uint              `json:"started_by"`
+	StartedAt    time.Time         `json:"started_at"`
+	Participants []RoomParticipant `json:"participants"`
+}
+
+// RoomParticipant is a member currently in a call room, together with their media state
+type RoomParticipant struct {
+	UserID     uint      `json:"user_id"`
+	DeviceID   string    `json:"device_id"`
+	Muted      bool      `json:"muted"`
+	Video      bool      `json:"video"`
+	HandRaised bool      `json:"hand_raised"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// RoomEvent is the payload of a room event, sent to every group member when a room
+// starts or ends and when a participant joins, leaves or changes their state
+type RoomEvent struct {
+	GroupID uint     `json:"group_id"`
+	Action  string   `json:"action"`
+	UserID  uint     `json:"user_id"`
+	Room    CallRoom `json:"room"`
+}
+
+// RoomSignalEvent relays a WebRTC signal between two participants of a room.
+// Rooms use a full mesh, so every pair of participants has its own peer connection.
+type RoomSignalEvent struct {
+	GroupID    uint            `json:"group_id"`
+	RoomID     string          `json:"room_id"`
+	FromUserID uint            `json:"from_user_id"`
+	FromDevice string          `json:"from_device"`
+	ToDevice   string          `json:"to_device"`
+	Type       string          `json:"type"` // "offer", "answer", "ice"
+	Payload    json.RawMessage `json:"payload"`
+}
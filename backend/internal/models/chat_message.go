@@ -11,7 +11,7 @@ type ChatMessage struct {
 
 	// Message details
 	Content string `bson:"content" json:"content"`
-	Type    string `bson:"type" json:"type"` // "text", "image", "file", "audio", "video"
+	Type    string `bson:"type" json:"type"` // "text", "image", "file", "audio", "video", "call", "system"
 
 	// Sender information (reference to MySQL User ID)
 	SenderID uint `bson:"sender_id" json:"sender_id"`
@@ -29,9 +29,15 @@ type ChatMessage struct {
 	// Attachments (for media messages)
 	Attachments []Attachment `bson:"attachments,omitempty" json:"attachments,omitempty"`
 
+	// Call summary (for "call" messages and group room "system" messages)
+	Call *CallSummary `bson:"call,omitempty" json:"call,omitempty"`
+
 	// Reply/Thread context
 	ReplyToID *primitive.ObjectID `bson:"reply_to_id,omitempty" json:"reply_to_id,omitempty"`
 
+	// Set per viewer when the viewer blocked the sender, clients hide these messages
+	SenderBlocked bool `bson:"-" json:"sender_blocked,omitempty"`
+
 	// Read receipts
 	ReadBy []ReadReceipt `bson:"read_by,omitempty" json:"read_by,omitempty"`
 
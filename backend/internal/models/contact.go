@@ -0,0 +1,7 @@
+package models
+
+// ContactMatch is an uploaded email hash that belongs to a registered user
+type ContactMatch struct {
+	Hash string `json:"hash"`
+	User User   `json:"user"`
+}
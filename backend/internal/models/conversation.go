@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -14,6 +15,9 @@ type Conversation struct {
 	Participants []uint `bson:"participants" json:"participants"`
 	GroupID      *uint  `bson:"group_id,omitempty" json:"group_id,omitempty"`
 
+	// Canonical key for direct messages (unique), empty for groups
+	DMKey string `bson:"dm_key,omitempty" json:"dm_key,omitempty"`
+
 	// Last message info (denormalized for performance)
 	LastMessageID   primitive.ObjectID `bson:"last_message_id" json:"last_message_id"`
 	LastMessageText string             `bson:"last_message_text" json:"last_message_text"`
@@ -23,6 +27,9 @@ type Conversation struct {
 	// Unread counts per user
 	UnreadCounts map[string]int `bson:"unread_counts" json:"unread_counts"` // key: user_id as string
 
+	// Read watermarks per user, used to recompute unread counts
+	ReadWatermarks map[string]time.Time `bson:"read_watermarks,omitempty" json:"read_watermarks,omitempty"` // key: user_id as string
+
 	// Timestamps
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
@@ -32,3 +39,12 @@ type Conversation struct {
 func (Conversation) CollectionName() string {
 	return "conversations"
 }
+
+// DMKey builds the canonical direct message key from two user IDs.
+// The IDs are sorted so both users resolve to the same conversation.
+func DMKey(userA, userB uint) string {
+	if userA > userB {
+		userA, userB = userB, userA
+	}
+	return fmt.Sprintf("dm:%d:%d", userA, userB)
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProtocolVersion is the version of the realtime event protocol.
+// Bump it when an event payload changes in a backward incompatible way.
+const ProtocolVersion = 1
+
+// Realtime event types (server → client)
+const (
+	EventHello          = "hello"
+	EventMessageCreated = "message.created"
+	EventMessageUpdated = "message.updated"
+	EventReceipt        = "receipt"
+	EventPresence       = "presence"
+	EventTyping         = "typing"
+	EventCall           = "call"
+	EventCallSignal     = "call.signal"
+	EventRoom           = "room"
+	EventRoomSignal     = "room.signal"
+	EventFriendship     = "friendship"
+	EventGroup          = "group"
+	EventJoinRequest    = "group.join_request"
+	EventPong           = "pong"
+	EventResync         = "resync"
+	EventError          = "error"
+)
+
+// Event is a realtime event delivered to connected clients.
+// Seq is a per-user, monotonically increasing sequence number set for events kept in the
+// event log, used to resume a stream; transient events such as typing have no Seq.
+type Event struct {
+	Seq       uint64      `json:"seq,omitempty"`
+	Version   int         `json:"v"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewEvent creates an event stamped with the current time and protocol version
+func NewEvent(eventType string, data interface{}) Event {
+	return Event{Version: ProtocolVersion, Type: eventType, Data: data, CreatedAt: time.Now().UTC()}
+}
+
+// InboundEvent is a frame sent by a client over the realtime channel.
+// Ref is echoed back in error events so the client can match them.
+type InboundEvent struct {
+	Type string          `json:"type"`
+	Ref  string          `json:"ref,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// HelloEvent is sent once after a connection is established
+type HelloEvent struct {
+	UserID       uint   `json:"user_id"`
+	DeviceID     string `json:"device_id"`
+	PingInterval int    `json:"ping_interval"` // seconds
+
+	// Seq is the latest event sequence number of the user, the starting point for resuming
+	Seq uint64 `json:"seq"`
+}
+
+// ResyncEvent tells a resuming client that some missed events are no longer retained.
+// The client must reload its state over the REST API, then continue from Seq.
+type ResyncEvent struct {
+	Seq uint64 `json:"seq"`
+}
+
+// ErrorEvent reports a failed inbound event
+type ErrorEvent struct {
+	Ref     string `json:"ref,omitempty"`
+	Message string `json:"message"`
+}
+
+// TypingEvent is the payload of a typing event
+type TypingEvent struct {
+	ConversationID string `json:"conversation_id"`
+	UserID         uint   `json:"user_id"`
+	Typing         bool   `json:"typing"`
+
+	// Receivers clear the indicator themselves once it expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// MessageEvent is the payload of message.created and message.updated events
+type MessageEvent struct {
+	ConversationID string      `json:"conversation_id"`
+	Message        ChatMessage `json:"message"`
+}
+
+// ReceiptEvent tells other participants that a user has read a conversation
+type ReceiptEvent struct {
+	ConversationID string    `json:"conversation_id"`
+	UserID         uint      `json:"user_id"`
+	ReadAt         time.Time `json:"read_at"`
+}
+
+// PresenceEvent is the payload of a presence event
+type PresenceEvent struct {
+	UserID   uint       `json:"user_id"`
+	Status   string     `json:"status"`              // "online", "away", "offline"
+	LastSeen *time.Time `json:"last_seen,omitempty"` // nil when the user hides their last seen
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatHTML = "html"
+	ExportFormatText = "text"
+)
+
+// Export job statuses
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+)
+
+// ExportJob tracks an asynchronous conversation export (stored in Redis)
+type ExportJob struct {
+	ID             string              `json:"id"`
+	UserID         uint                `json:"user_id"`
+	ConversationID string              `json:"conversation_id"`
+	Format         string              `json:"format"`
+	Status         string              `json:"status"`
+	Error          string              `json:"error,omitempty"`
+	MessageCount   int                 `json:"message_count"`
+	Attempts       int                 `json:"attempts,omitempty"` // times a worker picked the job up
+	FileID         *primitive.ObjectID `json:"file_id,omitempty"`  // GridFS file holding the zip
+	FileSize       int64               `json:"file_size,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	CompletedAt    *time.Time          `json:"completed_at,omitempty"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+}
+
+// ExportedMessage is a ChatMessage with the sender name resolved from MySQL
+type ExportedMessage struct {
+	ChatMessage
+	SenderName string `json:"sender_name"`
+
+	// Paths of the bundled attachments inside the zip, parallel to Attachments
+	// (empty when the file could not be downloaded)
+	AttachmentFiles []string `json:"attachment_files,omitempty"`
+}
@@ -1,11 +1,33 @@
 package models
 
-
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Friendship statuses
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+	FriendshipBlocked  = "blocked"
 )
 
-// Friendship represents a friend relationship between two users
+// Friendship actions reported in friendship events
+const (
+	FriendshipActionRequested = "requested"
+	FriendshipActionAccepted  = "accepted"
+	FriendshipActionDeclined  = "declined"
+	FriendshipActionCancelled = "cancelled"
+	FriendshipActionRemoved   = "removed"
+	FriendshipActionBlocked   = "blocked"
+	FriendshipActionUnblocked = "unblocked"
+)
+
+// Friendship represents a friend relationship between two users.
+// A pending friendship goes from UserID (the requester) to FriendID, and a blocked one
+// from UserID (the blocker) to FriendID. Blocks are kept separately from other friendships,
+// so two users can block each other.
 type Friendship struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
 	UserID     uint       `gorm:"not null;index:idx_user_friend" json:"user_id"`
@@ -13,6 +35,10 @@ type Friendship struct {
 	Status     string     `gorm:"type:enum('pending','accepted','blocked');default:'pending'" json:"status"`
 	CreatedAt  time.Time  `json:"created_at"`
 	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	UpdatedAt  time.Time  `gorm:"index" json:"updated_at"`
+
+	// Soft delete agar penghapusan pertemanan ikut tersinkron ke client (lihat /sync)
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	// Relationships
 	User   User `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -22,3 +48,24 @@ type Friendship struct {
 func (Friendship) TableName() string {
 	return "friendships"
 }
+
+// FriendshipEvent is the payload of a friendship event, sent to both users on every change.
+// Blocked and unblocked events only go to the blocker.
+type FriendshipEvent struct {
+	Action     string     `json:"action"`
+	Friendship Friendship `json:"friendship"`
+}
+
+// FriendSuggestion is a user the viewer may know, ranked by mutual friends then shared groups
+type FriendSuggestion struct {
+	User          User `json:"user"`
+	MutualFriends int  `json:"mutual_friends"`
+	SharedGroups  int  `json:"shared_groups"`
+}
+
+// FriendPresence is a friend together with their live presence
+type FriendPresence struct {
+	User     User       `json:"user"`
+	Status   string     `json:"status"`              // "online", "away", "offline"
+	LastSeen *time.Time `json:"last_seen,omitempty"` // nil when the friend hides their last seen
+}
@@ -17,6 +17,13 @@ type Group struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// IsOpen lets any user preview the group and join it (or request to join when private)
+	// without an invite link. Closed groups can only be reached through an invite link.
+	IsOpen bool `gorm:"not null;default:false" json:"is_open"`
+
+	// JoinQuestions are asked to users who request to join a private group
+	JoinQuestions []string `gorm:"type:text;serializer:json" json:"join_questions"`
+
 	// Relationships
 	Owner   User   `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
 	Members []User `gorm:"many2many:group_members;" json:"members,omitempty"`
@@ -24,11 +31,15 @@ type Group struct {
 
 // GroupMember represents the many-to-many relationship between users and groups
 type GroupMember struct {
-	ID       uint      `gorm:"primaryKey" json:"id"`
-	GroupID  uint      `gorm:"not null;index:idx_group_user" json:"group_id"`
-	UserID   uint      `gorm:"not null;index:idx_group_user" json:"user_id"`
-	Role     string    `gorm:"type:enum('admin','moderator','member');default:'member'" json:"role"`
-	JoinedAt time.Time `gorm:"autoCreateTime" json:"joined_at"`
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GroupID   uint      `gorm:"not null;index:idx_group_user" json:"group_id"`
+	UserID    uint      `gorm:"not null;index:idx_group_user" json:"user_id"`
+	Role      string    `gorm:"type:enum('admin','moderator','member');default:'member'" json:"role"`
+	JoinedAt  time.Time `gorm:"autoCreateTime" json:"joined_at"`
+	UpdatedAt time.Time `gorm:"index" json:"updated_at"`
+
+	// Soft delete agar anggota yang keluar ikut tersinkron ke client (lihat /sync)
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	// Relationships
 	Group Group `gorm:"foreignKey:GroupID" json:"group,omitempty"`
@@ -42,3 +53,65 @@ func (Group) TableName() string {
 func (GroupMember) TableName() string {
 	return "group_members"
 }
+
+// Group member roles
+const (
+	GroupRoleAdmin     = "admin"
+	GroupRoleModerator = "moderator"
+	GroupRoleMember    = "member"
+)
+
+// Group event actions
+const (
+	GroupActionCreated       = "created"
+	GroupActionUpdated       = "updated"
+	GroupActionDeleted       = "deleted"
+	GroupActionMembersAdded  = "members_added"
+	GroupActionMemberRemoved = "member_removed"
+
+	GroupActionPermissionsUpdated = "permissions_updated"
+	GroupActionMemberLeft         = "member_left"
+	GroupActionOwnerChanged       = "owner_changed"
+	GroupActionRoleChanged        = "role_changed"
+)
+
+// GroupSummary is a group in the caller's group list
+type GroupSummary struct {
+	Group       Group  `json:"group"`
+	Role        string `json:"role"` // the caller's role
+	MemberCount int64  `json:"member_count"`
+}
+
+// GroupMemberInfo is a member listed in group details
+type GroupMemberInfo struct {
+	User     User      `json:"user"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GroupDetail is a group with its members and their roles
+type GroupDetail struct {
+	Group   Group             `json:"group"`
+	Role    string            `json:"role"` // the caller's role
+	Members []GroupMemberInfo `json:"members"`
+}
+
+// GroupPreview is what a non-member can see about a group before joining
+type GroupPreview struct {
+	GroupID       uint     `json:"group_id"`
+	Name          string   `json:"name"`
+	AvatarURL     string   `json:"avatar_url"`
+	MemberCount   int64    `json:"member_count"`
+	IsPrivate     bool     `json:"is_private"`
+	IsOpen        bool     `json:"is_open"`
+	JoinQuestions []string `json:"join_questions"`
+	IsMember      bool     `json:"is_member"`
+}
+
+// GroupEvent is the payload of a group event. UserIDs lists the members that were added,
+// removed, left or got a new role, or the new owner.
+type GroupEvent struct {
+	Action  string `json:"action"`
+	Group   Group  `json:"group"`
+	UserIDs []uint `json:"user_ids,omitempty"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// GroupInvite is a shareable link to join a group (stored in MySQL)
+type GroupInvite struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	GroupID          uint       `gorm:"not null;index" json:"group_id"`
+	CreatorID        uint       `gorm:"not null" json:"creator_id"`
+	Token            string     `gorm:"size:64;not null;uniqueIndex" json:"token"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	MaxUses          int        `gorm:"not null;default:0" json:"max_uses"` // 0 means unlimited
+	Uses             int        `gorm:"not null;default:0" json:"uses"`
+	RequiresApproval bool       `gorm:"not null;default:false" json:"requires_approval"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Relationships
+	Creator User `gorm:"foreignKey:CreatorID" json:"creator,omitempty"`
+}
+
+func (GroupInvite) TableName() string {
+	return "group_invites"
+}
+
+// Usable reports whether the invite can still be used to join
+func (i GroupInvite) Usable(now time.Time) bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && !now.Before(*i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
+
+// Invite use statuses
+const (
+	GroupInviteUseJoined    = "joined"
+	GroupInviteUseRequested = "requested" // the link requires approval
+)
+
+// GroupInviteUse records a user joining (or asking to join) through an invite (stored in MySQL)
+type GroupInviteUse struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	InviteID  uint      `gorm:"not null;index" json:"invite_id"`
+	GroupID   uint      `gorm:"not null" json:"group_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Status    string    `gorm:"type:enum('joined','requested');not null" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (GroupInviteUse) TableName() string {
+	return "group_invite_uses"
+}
+
+// GroupInvitePreview is what anyone holding an invite link can see about the group
+type GroupInvitePreview struct {
+	GroupPreview
+	RequiresApproval bool       `json:"requires_approval"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
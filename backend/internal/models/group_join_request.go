@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Join request statuses
+const (
+	GroupJoinRequestPending  = "pending"
+	GroupJoinRequestApproved = "approved"
+	GroupJoinRequestRejected = "rejected"
+	GroupJoinRequestExpired  = "expired" // left pending for too long
+)
+
+// Join request event actions
+const (
+	GroupJoinRequestActionRequested = "requested"
+	GroupJoinRequestActionApproved  = "approved"
+	GroupJoinRequestActionRejected  = "rejected"
+	GroupJoinRequestActionExpired   = "expired"
+)
+
+// GroupJoinAnswer is the requester's answer to one of the group's join questions
+type GroupJoinAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// GroupJoinRequest is a request to join a group that has to be approved (stored in MySQL)
+type GroupJoinRequest struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	GroupID    uint       `gorm:"not null;index:idx_group_join_request" json:"group_id"`
+	UserID     uint       `gorm:"not null;index:idx_group_join_request" json:"user_id"`
+	InviteID   *uint      `json:"invite_id"` // the invite link used, if any
+	Status     string     `gorm:"type:enum('pending','approved','rejected','expired');default:'pending';index" json:"status"`
+	ReviewerID *uint      `json:"reviewer_id"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Answers to the group's join questions, asked when the request was made
+	Answers []GroupJoinAnswer `gorm:"type:text;serializer:json" json:"answers"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (GroupJoinRequest) TableName() string {
+	return "group_join_requests"
+}
+
+// GroupJoinRequestEvent is the payload of a group.join_request event
+type GroupJoinRequestEvent struct {
+	Action  string           `json:"action"`
+	Request GroupJoinRequest `json:"request"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Group permissions
+const (
+	PermissionSendMessages   = "send_messages"
+	PermissionEditInfo       = "edit_info" // name, description and avatar
+	PermissionAddMembers     = "add_members"
+	PermissionRemoveMembers  = "remove_members"
+	PermissionPinMessages    = "pin_messages"    // checked by message pinning once clients support it
+	PermissionDeleteMessages = "delete_messages" // other members' messages
+	PermissionChangeSettings = "change_settings" // privacy, member roles and role permissions
+	PermissionManageInvites  = "manage_invites"  // create and revoke invite links
+)
+
+// GroupPermissions lists every group permission
+var GroupPermissions = []string{
+	PermissionSendMessages,
+	PermissionEditInfo,
+	PermissionAddMembers,
+	PermissionRemoveMembers,
+	PermissionPinMessages,
+	PermissionDeleteMessages,
+	PermissionChangeSettings,
+	PermissionManageInvites,
+}
+
+// GroupRoles lists member roles from the most to the least privileged
+var GroupRoles = []string{GroupRoleAdmin, GroupRoleModerator, GroupRoleMember}
+
+// defaultGroupPermissions is the permission matrix of a group without overrides.
+// Admins always have every permission.
+var defaultGroupPermissions = map[string]map[string]bool{
+	GroupRoleModerator: {
+		PermissionSendMessages:   true,
+		PermissionAddMembers:     true,
+		PermissionRemoveMembers:  true,
+		PermissionPinMessages:    true,
+		PermissionDeleteMessages: true,
+	},
+	GroupRoleMember: {
+		PermissionSendMessages: true,
+	},
+}
+
+// GroupPermissionOverride changes one permission of one role in a group (stored in MySQL)
+type GroupPermissionOverride struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	GroupID    uint      `gorm:"not null;uniqueIndex:idx_group_role_permission" json:"group_id"`
+	Role       string    `gorm:"type:enum('moderator','member');not null;uniqueIndex:idx_group_role_permission" json:"role"`
+	Permission string    `gorm:"size:32;not null;uniqueIndex:idx_group_role_permission" json:"permission"`
+	Allowed    bool      `gorm:"not null" json:"allowed"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (GroupPermissionOverride) TableName() string {
+	return "group_permission_overrides"
+}
+
+// DefaultGroupPermission reports whether a role has a permission when the group has no override
+func DefaultGroupPermission(role, permission string) bool {
+	if role == GroupRoleAdmin {
+		return true
+	}
+	return defaultGroupPermissions[role][permission]
+}
+
+// GroupRolePermissions returns the effective permissions of a role given the group's overrides
+func GroupRolePermissions(role string, overrides []GroupPermissionOverride) map[string]bool {
+	permissions := make(map[string]bool, len(GroupPermissions))
+	for _, permission := range GroupPermissions {
+		permissions[permission] = DefaultGroupPermission(role, permission)
+	}
+	if role == GroupRoleAdmin {
+		return permissions
+	}
+	for _, override := range overrides {
+		// overrides of permissions that no longer exist are ignored
+		if _, known := permissions[override.Permission]; known && override.Role == role {
+			permissions[override.Permission] = override.Allowed
+		}
+	}
+	return permissions
+}
+
+// GroupRoleRank orders roles by privilege, higher is more privileged
+func GroupRoleRank(role string) int {
+	switch role {
+	case GroupRoleAdmin:
+		return 2
+	case GroupRoleModerator:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Privacy audiences: who may see or do something
+const (
+	PrivacyEveryone = "everyone"
+	PrivacyFriends  = "friends"
+	PrivacyNobody   = "nobody"
+)
+
+// PrivacySettings holds a user's privacy preferences (stored in MySQL).
+// Users without a row use DefaultPrivacySettings.
+type PrivacySettings struct {
+	UserID         uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	DirectMessages string    `gorm:"type:enum('everyone','friends','nobody');default:'everyone'" json:"direct_messages"` // who can send me direct messages
+	OnlineStatus   string    `gorm:"type:enum('everyone','friends','nobody');default:'everyone'" json:"online_status"`   // who can see whether I am online
+	LastSeen       string    `gorm:"type:enum('everyone','friends','nobody');default:'everyone'" json:"last_seen"`       // who can see my last seen
+	GroupAdd       string    `gorm:"type:enum('everyone','friends','nobody');default:'everyone'" json:"group_add"`       // who can add me to groups
+	Searchable     bool      `gorm:"not null;default:true" json:"searchable"`                                            // whether others can find me by username
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (PrivacySettings) TableName() string {
+	return "privacy_settings"
+}
+
+// DefaultPrivacySettings returns the settings of a user who never changed them
+func DefaultPrivacySettings(userID uint) PrivacySettings {
+	return PrivacySettings{
+		UserID:         userID,
+		DirectMessages: PrivacyEveryone,
+		OnlineStatus:   PrivacyEveryone,
+		LastSeen:       PrivacyEveryone,
+		GroupAdd:       PrivacyEveryone,
+		Searchable:     true,
+	}
+}
+
+// PrivacyAllows reports whether a viewer is in the audience. friend tells whether the
+// viewer is an accepted friend of the user who chose the audience.
+func PrivacyAllows(audience string, friend bool) bool {
+	switch audience {
+	case PrivacyEveryone:
+		return true
+	case PrivacyFriends:
+		return friend
+	default:
+		return false
+	}
+}
+
+// VisiblePresence returns the status and last seen a viewer may see. A hidden online
+// status shows as "offline" and a hidden last seen as the zero time.
+func (s PrivacySettings) VisiblePresence(status string, lastSeen time.Time, friend bool) (string, time.Time) {
+	if !PrivacyAllows(s.OnlineStatus, friend) {
+		return "offline", time.Time{}
+	}
+	if !PrivacyAllows(s.LastSeen, friend) {
+		return status, time.Time{}
+	}
+	return status, lastSeen
+}
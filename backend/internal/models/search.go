@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MessageSearchFilter holds the optional filters for full-text message search
+type MessageSearchFilter struct {
+	Query         string
+	SenderID      *uint
+	Conversation  *Conversation
+	Since         *time.Time
+	Until         *time.Time
+	HasAttachment bool
+	Type          string
+	Page          int64
+	Limit         int64
+}
+
+// MessageSearchResult is a single search hit with its relevance score
+type MessageSearchResult struct {
+	ChatMessage `bson:",inline"`
+	Score       float64     `bson:"score" json:"score"`
+	Highlights  []TextRange `bson:"-" json:"highlights"`
+}
+
+// TextRange marks a matched part of a message content (rune offsets, end exclusive)
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
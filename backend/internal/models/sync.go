@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// SyncCursor is the position of the last change sent to the client for one data source.
+// Changes are ordered by (updated_at, id) so rows with the same timestamp are never skipped.
+type SyncCursor struct {
+	UpdatedAt time.Time `json:"t"`
+	ID        string    `json:"id,omitempty"`
+}
+
+// SyncToken is the decoded form of the opaque token returned by /sync
+type SyncToken struct {
+	Version       int        `json:"v"`
+	Messages      SyncCursor `json:"m"`
+	Conversations SyncCursor `json:"c"`
+	Friendships   SyncCursor `json:"f"`
+	GroupMembers  SyncCursor `json:"g"`
+	Users         SyncCursor `json:"u"`
+}
+
+// SyncChanges holds every change since the previous sync token.
+// Deleted messages have is_deleted set, removed friendships and memberships have deleted_at set.
+type SyncChanges struct {
+	Messages      []ChatMessage  `json:"messages"`
+	Conversations []Conversation `json:"conversations"`
+	Friendships   []Friendship   `json:"friendships"`
+	GroupMembers  []GroupMember  `json:"group_members"`
+	Users         []User         `json:"users"`
+}
+
+// SyncPage is one bounded page of changes. Clients keep calling /sync with NextToken
+// while HasMore is true.
+type SyncPage struct {
+	Changes   SyncChanges `json:"changes"`
+	NextToken string      `json:"next_token"`
+	HasMore   bool        `json:"has_more"`
+}
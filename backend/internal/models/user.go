@@ -19,6 +19,11 @@ type User struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Contact discovery: salted SHA-256 of the normalized email, matched against hashes
+	// uploaded from address books. Users who are not discoverable are never matched.
+	EmailHash    string `gorm:"size:64;index" json:"-"`
+	Discoverable bool   `gorm:"default:true" json:"discoverable"`
+
 	// Relationships
 	Friends     []User  `gorm:"many2many:friendships;" json:"friends,omitempty"`
 	Groups      []Group `gorm:"many2many:group_members;" json:"groups,omitempty"`
@@ -29,4 +34,10 @@ type User struct {
 // TableName overrides for custom table names (optional)
 func (User) TableName() string {
 	return "users"
-}
\ No newline at end of file
+}
+
+// HidePresence clears the online status and last seen time, for viewers who may not see them
+func (u *User) HidePresence() {
+	u.Status = "offline"
+	u.LastSeen = time.Time{}
+}
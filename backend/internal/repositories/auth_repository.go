@@ -9,7 +9,7 @@ import (
 
 // Interface defining the methods for AuthRepository
 type AuthRepository interface {
-	SyncUser(uid, email, name, avatarURL string) (*models.User, error)
+	SyncUser(uid, email, emailHash, name, avatarURL string) (*models.User, error)
 }
 
 // Semacam class yang mengimplementasikan AuthRepository
@@ -21,7 +21,7 @@ func NewAuthRepository(mysqlDB *gorm.DB) AuthRepository {
 	return &authRepository{mysqlDB: mysqlDB}
 }
 
-func (ar *authRepository) SyncUser(uid, email, name, avatarURL string) (*models.User, error) {
+func (ar *authRepository) SyncUser(uid, email, emailHash, name, avatarURL string) (*models.User, error) {
 	// Inisialisasi user dengan FirebaseUID yang akan dicari
 	user := models.User{
         FirebaseUID: uid,
@@ -31,12 +31,20 @@ func (ar *authRepository) SyncUser(uid, email, name, avatarURL string) (*models.
 	err := ar.mysqlDB.Where("firebase_uid = ?", uid).
         Attrs(models.User{ // Attrs hanya dipakai jika record tidak ada
             Email:     email,
+            EmailHash: emailHash,
             FullName:  name,
             AvatarURL: avatarURL,
             Status:    "offline",
             LastSeen:  time.Now(),
         }).
         FirstOrCreate(&user).Error
-
+	if err != nil {
+		return nil, err
+	}
+
+	// user lama (atau setelah salt diganti) mendapat hash email baru saat login
+	if user.EmailHash != emailHash {
+		err = ar.mysqlDB.Model(&user).UpdateColumn("email_hash", emailHash).Error
+	}
 	return &user, err
 }
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	callDeadlinesKey = "call:deadlines"
+
+	// callRetention adalah lama call yang sudah selesai tetap bisa dibaca
+	callRetention = time.Hour
+
+	callUpdateAttempts = 5
+)
+
+// CallRepository menyimpan sesi call di Redis. Setiap user hanya bisa berada di satu call,
+// dan deadline semua call yang belum selesai disimpan di satu sorted set agar timeout bisa
+// diproses dari instance mana pun.
+type CallRepository interface {
+	Create(call *models.Call) (bool, error)
+	Find(id string) (*models.Call, error)
+	Update(id string, update func(call *models.Call) error) (*models.Call, error)
+	Expired(now time.Time, limit int64) ([]string, error)
+	RemoveDeadline(id string) error
+}
+
+type callRepository struct {
+	redisClient *redis.Client
+}
+
+func NewCallRepository(redisClient *redis.Client) CallRepository {
+	return &callRepository{redisClient: redisClient}
+}
+
+func callKey(id string) string {
+	return fmt.Sprintf("call:%s", id)
+}
+
+func callUserKey(userID uint) string {
+	return fmt.Sprintf("call:user:%d", userID)
+}
+
+// createCallScript menyimpan call baru hanya jika kedua user sedang tidak berada di call lain
+var createCallScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 or redis.call("EXISTS", KEYS[3]) == 1 then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+redis.call("SET", KEYS[2], ARGV[3], "PX", ARGV[2])
+redis.call("SET", KEYS[3], ARGV[3], "PX", ARGV[2])
+redis.call("ZADD", KEYS[4], ARGV[4], ARGV[3])
+return 1
+`)
+
+// Create menyimpan call baru, mengembalikan false jika salah satu user masih berada di call lain
+func (cr *callRepository) Create(call *models.Call) (bool, error) {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := time.Until(call.ExpiresAt) + callRetention
+	created, err := createCallScript.Run(context.Background(), cr.redisClient,
+		[]string{callKey(call.ID), callUserKey(call.CallerID), callUserKey(call.CalleeID), callDeadlinesKey},
+		data, ttl.Milliseconds(), call.ID, call.ExpiresAt.UnixMilli(),
+	).Int()
+	return created == 1, err
+}
+
+func (cr *callRepository) Find(id string) (*models.Call, error) {
+	data, err := cr.redisClient.Get(context.Background(), callKey(id)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	call := models.Call{}
+	err = json.Unmarshal(data, &call)
+	return &call, err
+}
+
+// Update membaca call, menjalankan update, lalu menyimpan hasilnya secara optimistic (WATCH).
+// Error dari update membatalkan perubahan dan dikembalikan apa adanya. Call yang selesai
+// dilepas dari kedua user dan dari daftar deadline.
+func (cr *callRepository) Update(id string, update func(call *models.Call) error) (*models.Call, error) {
+	ctx := context.Background()
+	key := callKey(id)
+	var call models.Call
+
+	for attempt := 0; attempt < callUpdateAttempts; attempt++ {
+		err := cr.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, key).Bytes()
+			if err != nil {
+				return err
+			}
+			call = models.Call{}
+			if err := json.Unmarshal(data, &call); err != nil {
+				return err
+			}
+			if err := update(&call); err != nil {
+				return err
+			}
+			if data, err = json.Marshal(&call); err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				userKeys := []string{callUserKey(call.CallerID), callUserKey(call.CalleeID)}
+				if call.Finished() {
+					pipe.Set(ctx, key, data, callRetention)
+					pipe.Del(ctx, userKeys...)
+					pipe.ZRem(ctx, callDeadlinesKey, call.ID)
+					return nil
+				}
+
+				ttl := time.Until(call.ExpiresAt) + callRetention
+				pipe.Set(ctx, key, data, ttl)
+				for _, userKey := range userKeys {
+					pipe.Expire(ctx, userKey, ttl)
+				}
+				pipe.ZAdd(ctx, callDeadlinesKey, &redis.Z{Score: float64(call.ExpiresAt.UnixMilli()), Member: call.ID})
+				return nil
+			})
+			return err
+		}, key)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &call, nil
+	}
+	return nil, redis.TxFailedErr
+}
+
+// Expired mengambil ID call yang deadline-nya sudah lewat
+func (cr *callRepository) Expired(now time.Time, limit int64) ([]string, error) {
+	return cr.redisClient.ZRangeByScore(context.Background(), callDeadlinesKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+}
+
+// RemoveDeadline membuang deadline call yang datanya sudah tidak ada di Redis
+func (cr *callRepository) RemoveDeadline(id string) error {
+	return cr.redisClient.ZRem(context.Background(), callDeadlinesKey, id).Err()
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// changedSince membatasi query MySQL ke baris yang berubah setelah cursor (updated_at, id)
+// sampai batas until, diurutkan untuk keyset pagination
+func changedSince(query *gorm.DB, since time.Time, afterID uint, until time.Time, limit int) *gorm.DB {
+	return query.
+		Where("(updated_at > ? OR (updated_at = ? AND id > ?)) AND updated_at <= ?", since, since, afterID, until).
+		Order("updated_at, id").
+		Limit(limit)
+}
+
+// changedSinceFilter adalah versi MongoDB dari changedSince
+func changedSinceFilter(since time.Time, afterID primitive.ObjectID, until time.Time) bson.M {
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"updated_at": bson.M{"$gt": since}},
+			bson.M{"updated_at": since, "_id": bson.M{"$gt": afterID}},
+		},
+		"updated_at": bson.M{"$lte": until},
+	}
+}
+
+func changedSinceOptions(limit int64) *options.FindOptions {
+	return options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+}
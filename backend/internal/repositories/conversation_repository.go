@@ -0,0 +1,282 @@
+package repositories
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ConversationRepository interface {
+	FindByID(id primitive.ObjectID) (*models.Conversation, error)
+	FindDM(userA, userB uint) (*models.Conversation, error)
+	FindByGroup(groupID uint) (*models.Conversation, error)
+	FindByMember(userID uint, groupIDs []uint) ([]models.Conversation, error)
+	FindBatch(afterID primitive.ObjectID, limit int64) ([]models.Conversation, error)
+	SetUnreadCount(id primitive.ObjectID, userID uint, count int) error
+	MarkRead(id primitive.ObjectID, userID uint, readAt time.Time) error
+	FindOrCreateDM(userA, userB uint) (*models.Conversation, error)
+	FindOrCreateGroup(groupID uint) (*models.Conversation, error)
+	RecordMessage(id primitive.ObjectID, message *models.ChatMessage, preview string, recipientIDs []uint) error
+	FindChangedSince(userID uint, groupIDs []uint, since time.Time, afterID primitive.ObjectID, until time.Time, limit int64) ([]models.Conversation, error)
+	EnsureIndexes() error
+}
+
+type conversationRepository struct {
+	mongoDB *mongo.Database
+}
+
+func NewConversationRepository(mongoDB *mongo.Database) ConversationRepository {
+	return &conversationRepository{mongoDB: mongoDB}
+}
+
+// mongoContext membuat context dengan timeout untuk query MongoDB
+func mongoContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 10*time.Second)
+}
+
+func (cr *conversationRepository) collection() *mongo.Collection {
+	return cr.mongoDB.Collection(models.Conversation{}.CollectionName())
+}
+
+func (cr *conversationRepository) FindByID(id primitive.ObjectID) (*models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	conversation := models.Conversation{}
+	err := cr.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&conversation)
+	return &conversation, err
+}
+
+// FindDM mengambil conversation DM dua user tanpa membuatnya jika belum ada
+func (cr *conversationRepository) FindDM(userA, userB uint) (*models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	conversation := models.Conversation{}
+	err := cr.collection().FindOne(ctx, bson.M{"dm_key": models.DMKey(userA, userB)}).Decode(&conversation)
+	return &conversation, err
+}
+
+// FindByGroup mengambil conversation milik grup tanpa membuatnya jika belum ada
+func (cr *conversationRepository) FindByGroup(groupID uint) (*models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	conversation := models.Conversation{}
+	err := cr.collection().FindOne(ctx, bson.M{"group_id": groupID}).Decode(&conversation)
+	return &conversation, err
+}
+
+// memberFilter memilih conversation milik user: DM (participants) dan grup yang diikuti
+func memberFilter(userID uint, groupIDs []uint) bson.M {
+	if len(groupIDs) == 0 {
+		return bson.M{"participants": userID}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"participants": userID},
+		bson.M{"group_id": bson.M{"$in": groupIDs}},
+	}}
+}
+
+// FindByMember mengambil semua conversation milik user
+func (cr *conversationRepository) FindByMember(userID uint, groupIDs []uint) ([]models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := memberFilter(userID, groupIDs)
+	opts := options.Find().SetSort(bson.D{{Key: "last_message_at", Value: -1}})
+	cursor, err := cr.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := []models.Conversation{}
+	err = cursor.All(ctx, &conversations)
+	return conversations, err
+}
+
+// FindBatch mengambil conversation secara berurutan berdasarkan _id (untuk job yang memproses semua data)
+func (cr *conversationRepository) FindBatch(afterID primitive.ObjectID, limit int64) ([]models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := bson.M{}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := cr.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := []models.Conversation{}
+	err = cursor.All(ctx, &conversations)
+	return conversations, err
+}
+
+func (cr *conversationRepository) SetUnreadCount(id primitive.ObjectID, userID uint, count int) error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	_, err := cr.collection().UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{fmt.Sprintf("unread_counts.%d", userID): count},
+	})
+	return err
+}
+
+// MarkRead memajukan read watermark user dan mereset unread count menjadi 0
+func (cr *conversationRepository) MarkRead(id primitive.ObjectID, userID uint, readAt time.Time) error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	_, err := cr.collection().UpdateByID(ctx, id, bson.M{
+		"$max": bson.M{fmt.Sprintf("read_watermarks.%d", userID): readAt},
+		"$set": bson.M{
+			fmt.Sprintf("unread_counts.%d", userID): 0,
+			// status baca ikut tersinkron ke device lain lewat /sync
+			"updated_at": time.Now().UTC(),
+		},
+	})
+	return err
+}
+
+// FindOrCreateDM mengambil conversation DM berdasarkan dm_key, atau membuatnya jika belum ada.
+// Unique index pada dm_key menjamin tidak ada duplikat saat request bersamaan.
+func (cr *conversationRepository) FindOrCreateDM(userA, userB uint) (*models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	dmKey := models.DMKey(userA, userB)
+	if userA > userB {
+		userA, userB = userB, userA
+	}
+
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{
+		"participants":      []uint{userA, userB},
+		"dm_key":            dmKey,
+		"last_message_id":   primitive.NilObjectID,
+		"last_message_text": "",
+		"last_message_at":   now,
+		"last_sender_id":    0,
+		"unread_counts":     bson.M{},
+		"created_at":        now,
+		"updated_at":        now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	conversation := models.Conversation{}
+	err := cr.collection().FindOneAndUpdate(ctx, bson.M{"dm_key": dmKey}, update, opts).Decode(&conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		// request lain sudah membuat conversation yang sama, ambil yang sudah ada
+		err = cr.collection().FindOne(ctx, bson.M{"dm_key": dmKey}).Decode(&conversation)
+	}
+	return &conversation, err
+}
+
+// FindOrCreateGroup mengambil conversation milik grup, atau membuatnya jika belum ada
+func (cr *conversationRepository) FindOrCreateGroup(groupID uint) (*models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{
+		"participants":      []uint{},
+		"group_id":          groupID,
+		"last_message_id":   primitive.NilObjectID,
+		"last_message_text": "",
+		"last_message_at":   now,
+		"last_sender_id":    0,
+		"unread_counts":     bson.M{},
+		"created_at":        now,
+		"updated_at":        now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	conversation := models.Conversation{}
+	err := cr.collection().FindOneAndUpdate(ctx, bson.M{"group_id": groupID}, update, opts).Decode(&conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		err = cr.collection().FindOne(ctx, bson.M{"group_id": groupID}).Decode(&conversation)
+	}
+	return &conversation, err
+}
+
+// RecordMessage memperbarui ringkasan pesan terakhir dan menambah unread count penerima
+func (cr *conversationRepository) RecordMessage(id primitive.ObjectID, message *models.ChatMessage, preview string, recipientIDs []uint) error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"last_message_id":   message.ID,
+		"last_message_text": preview,
+		"last_message_at":   message.CreatedAt,
+		"last_sender_id":    message.SenderID,
+		"updated_at":        message.CreatedAt,
+	}}
+	if len(recipientIDs) > 0 {
+		inc := bson.M{}
+		for _, recipientID := range recipientIDs {
+			inc[fmt.Sprintf("unread_counts.%d", recipientID)] = 1
+		}
+		update["$inc"] = inc
+	}
+
+	_, err := cr.collection().UpdateByID(ctx, id, update)
+	return err
+}
+
+// FindChangedSince mengambil ringkasan conversation user yang berubah setelah cursor
+func (cr *conversationRepository) FindChangedSince(userID uint, groupIDs []uint, since time.Time, afterID primitive.ObjectID, until time.Time, limit int64) ([]models.Conversation, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := bson.M{"$and": bson.A{memberFilter(userID, groupIDs), changedSinceFilter(since, afterID, until)}}
+	cursor, err := cr.collection().Find(ctx, filter, changedSinceOptions(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := []models.Conversation{}
+	err = cursor.All(ctx, &conversations)
+	return conversations, err
+}
+
+// EnsureIndexes membuat index yang dibutuhkan collection conversations
+func (cr *conversationRepository) EnsureIndexes() error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	_, err := cr.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "dm_key", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_dm_key").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"dm_key": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_group_id").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"group_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "participants", Value: 1}, {Key: "last_message_at", Value: -1}},
+			Options: options.Index().SetName("participants_last_message_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("updated_at_id"),
+		},
+	})
+	return err
+}
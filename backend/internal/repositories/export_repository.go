@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	exportQueueKey   = "export:queue"
+	exportWorkersKey = "export:workers"
+)
+
+// exportProcessingKey adalah list job yang sedang dikerjakan satu worker
+func exportProcessingKey(workerID string) string {
+	return fmt.Sprintf("export:processing:%s", workerID)
+}
+
+// exportHeartbeatKey menandai worker masih hidup, hilang sendiri jika instance mati
+func exportHeartbeatKey(workerID string) string {
+	return fmt.Sprintf("export:worker:%s", workerID)
+}
+
+func exportJobTokenKey(jobID string) string {
+	return fmt.Sprintf("export:job:%s:download", jobID)
+}
+
+// ExportRepository menyimpan job export di Redis dan file hasil export di GridFS,
+// sehingga job bisa diproses dan diunduh dari instance backend mana pun.
+type ExportRepository interface {
+	SaveJob(job *models.ExportJob, ttl time.Duration) error
+	FindJob(id string) (*models.ExportJob, error)
+	Enqueue(jobID string) error
+	Dequeue(workerID string, timeout time.Duration) (string, error)
+	Ack(workerID, jobID string) error
+	Heartbeat(workerIDs []string, ttl time.Duration) error
+	ReclaimStale() (int, error)
+	UploadFile(filename string, source io.Reader, expiresAt time.Time) (primitive.ObjectID, error)
+	OpenFile(id primitive.ObjectID) (io.ReadCloser, int64, error)
+	DeleteExpiredFiles(now time.Time) (int, error)
+	SaveDownloadToken(token, jobID string, ttl time.Duration) error
+	FindDownloadToken(token string) (string, error)
+	FindJobDownloadToken(jobID string) (string, error)
+}
+
+type exportRepository struct {
+	mongoDB     *mongo.Database
+	redisClient *redis.Client
+}
+
+func NewExportRepository(mongoDB *mongo.Database, redisClient *redis.Client) ExportRepository {
+	return &exportRepository{mongoDB: mongoDB, redisClient: redisClient}
+}
+
+func (er *exportRepository) bucket() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(er.mongoDB, options.GridFSBucket().SetName("exports"))
+}
+
+func (er *exportRepository) SaveJob(job *models.ExportJob, ttl time.Duration) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return er.redisClient.Set(context.Background(), fmt.Sprintf("export:job:%s", job.ID), data, ttl).Err()
+}
+
+func (er *exportRepository) FindJob(id string) (*models.ExportJob, error) {
+	data, err := er.redisClient.Get(context.Background(), fmt.Sprintf("export:job:%s", id)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	job := models.ExportJob{}
+	err = json.Unmarshal(data, &job)
+	return &job, err
+}
+
+func (er *exportRepository) Enqueue(jobID string) error {
+	return er.redisClient.LPush(context.Background(), exportQueueKey, jobID).Err()
+}
+
+// Dequeue menunggu job berikutnya dan memindahkannya secara atomik ke list processing
+// milik worker, sehingga job tidak hilang jika instance mati sebelum Ack.
+// Mengembalikan redis.Nil jika timeout.
+func (er *exportRepository) Dequeue(workerID string, timeout time.Duration) (string, error) {
+	return er.redisClient.BLMove(context.Background(), exportQueueKey, exportProcessingKey(workerID), "RIGHT", "LEFT", timeout).Result()
+}
+
+// Ack mengeluarkan job yang sudah selesai diproses dari list processing worker
+func (er *exportRepository) Ack(workerID, jobID string) error {
+	return er.redisClient.LRem(context.Background(), exportProcessingKey(workerID), 1, jobID).Err()
+}
+
+// Heartbeat mendaftarkan worker dan memperpanjang tanda hidupnya
+func (er *exportRepository) Heartbeat(workerIDs []string, ttl time.Duration) error {
+	ctx := context.Background()
+	_, err := er.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, workerID := range workerIDs {
+			pipe.SAdd(ctx, exportWorkersKey, workerID)
+			pipe.Set(ctx, exportHeartbeatKey(workerID), 1, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// ReclaimStale mengembalikan job milik worker yang heartbeat-nya sudah hilang ke
+// depan antrian. LMOVE atomik, jadi aman dijalankan bersamaan dari beberapa instance.
+func (er *exportRepository) ReclaimStale() (int, error) {
+	ctx := context.Background()
+	workerIDs, err := er.redisClient.SMembers(ctx, exportWorkersKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	reclaimed := 0
+	for _, workerID := range workerIDs {
+		alive, err := er.redisClient.Exists(ctx, exportHeartbeatKey(workerID)).Result()
+		if err != nil {
+			return reclaimed, err
+		}
+		if alive > 0 {
+			continue
+		}
+
+		for {
+			err := er.redisClient.LMove(ctx, exportProcessingKey(workerID), exportQueueKey, "RIGHT", "RIGHT").Err()
+			if errors.Is(err, redis.Nil) {
+				break
+			}
+			if err != nil {
+				return reclaimed, err
+			}
+			reclaimed++
+		}
+		if err := er.redisClient.SRem(ctx, exportWorkersKey, workerID).Err(); err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+func (er *exportRepository) UploadFile(filename string, source io.Reader, expiresAt time.Time) (primitive.ObjectID, error) {
+	bucket, err := er.bucket()
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	opts := options.GridFSUpload().SetMetadata(bson.M{"expires_at": expiresAt})
+	return bucket.UploadFromStream(filename, source, opts)
+}
+
+func (er *exportRepository) OpenFile(id primitive.ObjectID) (io.ReadCloser, int64, error) {
+	bucket, err := er.bucket()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stream, err := bucket.OpenDownloadStream(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return stream, stream.GetFile().Length, nil
+}
+
+// DeleteExpiredFiles menghapus file export yang sudah melewati masa simpan
+func (er *exportRepository) DeleteExpiredFiles(now time.Time) (int, error) {
+	bucket, err := er.bucket()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	cursor, err := bucket.FindContext(ctx, bson.M{"metadata.expires_at": bson.M{"$lt": now}})
+	if err != nil {
+		return 0, err
+	}
+
+	files := []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}{}
+	if err := cursor.All(ctx, &files); err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := bucket.DeleteContext(ctx, file.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+// SaveDownloadToken menyimpan token download beserta token aktif milik job,
+// supaya polling status job memakai token yang sama sampai kedaluwarsa
+func (er *exportRepository) SaveDownloadToken(token, jobID string, ttl time.Duration) error {
+	ctx := context.Background()
+	_, err := er.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf("export:download:%s", token), jobID, ttl)
+		pipe.Set(ctx, exportJobTokenKey(jobID), token, ttl)
+		return nil
+	})
+	return err
+}
+
+func (er *exportRepository) FindDownloadToken(token string) (string, error) {
+	return er.redisClient.Get(context.Background(), fmt.Sprintf("export:download:%s", token)).Result()
+}
+
+func (er *exportRepository) FindJobDownloadToken(jobID string) (string, error) {
+	return er.redisClient.Get(context.Background(), exportJobTokenKey(jobID)).Result()
+}
@@ -0,0 +1,303 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Jenis daftar friendship untuk FriendshipRepository.List
+const (
+	FriendshipListAccepted = "accepted"
+	FriendshipListIncoming = "incoming"
+	FriendshipListOutgoing = "outgoing"
+	FriendshipListBlocked  = "blocked"
+)
+
+type FriendshipRepository interface {
+	FriendIDs(userID uint) ([]uint, error)
+	FriendEdges(userIDs []uint) ([]models.Friendship, error)
+	RelatedIDs(userID uint) ([]uint, error)
+	FindByID(id uint) (*models.Friendship, error)
+	FindBetween(userA, userB uint) (*models.Friendship, error)
+	Request(userID, friendID uint) (*models.Friendship, bool, error)
+	UpdateStatus(id uint, from, to string, acceptedAt *time.Time) (bool, error)
+	Delete(id uint, status string) (bool, error)
+	List(userID uint, kind string, offset, limit int) ([]models.Friendship, int64, error)
+	Block(userID, targetID uint) (*models.Friendship, *models.Friendship, error)
+	FindBlock(userID, targetID uint) (*models.Friendship, error)
+	Blocked(userA, userB uint) (bool, error)
+	BlockedIDs(userID uint) ([]uint, error)
+	BlockerIDs(userID uint) ([]uint, error)
+	FindChangedSince(userID uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.Friendship, error)
+}
+
+type friendshipRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewFriendshipRepository(mysqlDB *gorm.DB) FriendshipRepository {
+	return &friendshipRepository{mysqlDB: mysqlDB}
+}
+
+// FriendIDs mengambil ID teman yang sudah accepted (friendship disimpan satu arah)
+func (fr *friendshipRepository) FriendIDs(userID uint) ([]uint, error) {
+	friendships := []models.Friendship{}
+	err := fr.mysqlDB.
+		Where("status = ? AND (user_id = ? OR friend_id = ?)", models.FriendshipAccepted, userID, userID).
+		Find(&friendships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(friendships))
+	for _, friendship := range friendships {
+		if friendship.UserID == userID {
+			ids = append(ids, friendship.FriendID)
+		} else {
+			ids = append(ids, friendship.UserID)
+		}
+	}
+	return ids, nil
+}
+
+// FriendEdges mengambil semua pertemanan accepted yang melibatkan salah satu dari userIDs,
+// hanya kolom user_id dan friend_id
+func (fr *friendshipRepository) FriendEdges(userIDs []uint) ([]models.Friendship, error) {
+	friendships := []models.Friendship{}
+	if len(userIDs) == 0 {
+		return friendships, nil
+	}
+	err := fr.mysqlDB.
+		Select("user_id, friend_id").
+		Where("status = ? AND (user_id IN ? OR friend_id IN ?)", models.FriendshipAccepted, userIDs, userIDs).
+		Find(&friendships).Error
+	return friendships, err
+}
+
+// RelatedIDs mengambil ID semua user yang punya friendship apa pun dengan userID:
+// teman, permintaan pending dari dan ke user, serta block dari kedua arah
+func (fr *friendshipRepository) RelatedIDs(userID uint) ([]uint, error) {
+	friendships := []models.Friendship{}
+	err := fr.mysqlDB.
+		Select("user_id, friend_id").
+		Where("user_id = ? OR friend_id = ?", userID, userID).
+		Find(&friendships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(friendships))
+	for _, friendship := range friendships {
+		if friendship.UserID == userID {
+			ids = append(ids, friendship.FriendID)
+		} else {
+			ids = append(ids, friendship.UserID)
+		}
+	}
+	return ids, nil
+}
+
+func (fr *friendshipRepository) FindByID(id uint) (*models.Friendship, error) {
+	friendship := models.Friendship{}
+	err := fr.mysqlDB.Preload("User").Preload("Friend").First(&friendship, id).Error
+	return &friendship, err
+}
+
+// FindBetween mengambil friendship antara dua user dari arah mana pun
+func (fr *friendshipRepository) FindBetween(userA, userB uint) (*models.Friendship, error) {
+	friendship := models.Friendship{}
+	err := fr.mysqlDB.
+		Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)", userA, userB, userB, userA).
+		First(&friendship).Error
+	return &friendship, err
+}
+
+// Request membuat permintaan pertemanan pending dari userID ke friendID. Baris kedua user
+// dikunci selama transaksi, sehingga permintaan bersamaan dari dua arah tidak menghasilkan
+// dua friendship. Jika sudah ada friendship di antara keduanya, friendship tersebut
+// dikembalikan dengan created false.
+func (fr *friendshipRepository) Request(userID, friendID uint) (*models.Friendship, bool, error) {
+	friendship := &models.Friendship{}
+	created := false
+
+	err := fr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		locked := []uint{}
+		err := tx.Model(&models.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{userID, friendID}).
+			Order("id").
+			Pluck("id", &locked).Error
+		if err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err = tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)", userID, friendID, friendID, userID).
+			First(friendship).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		*friendship = models.Friendship{UserID: userID, FriendID: friendID, Status: models.FriendshipPending}
+		created = true
+		return tx.Create(friendship).Error
+	})
+	return friendship, created, err
+}
+
+// UpdateStatus mengubah status friendship hanya jika statusnya masih from,
+// mengembalikan false jika status sudah berubah lebih dulu
+func (fr *friendshipRepository) UpdateStatus(id uint, from, to string, acceptedAt *time.Time) (bool, error) {
+	result := fr.mysqlDB.Model(&models.Friendship{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "accepted_at": acceptedAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Delete menghapus (soft delete) friendship dengan status tertentu. updated_at ikut diubah
+// agar penghapusan terbaca oleh /sync.
+func (fr *friendshipRepository) Delete(id uint, status string) (bool, error) {
+	now := time.Now()
+	result := fr.mysqlDB.Model(&models.Friendship{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(map[string]interface{}{"deleted_at": now, "updated_at": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+// List mengambil friendship user beserta profil kedua user, terbaru lebih dulu
+func (fr *friendshipRepository) List(userID uint, kind string, offset, limit int) ([]models.Friendship, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		switch kind {
+		case FriendshipListIncoming:
+			return db.Where("friend_id = ? AND status = ?", userID, models.FriendshipPending)
+		case FriendshipListOutgoing:
+			return db.Where("user_id = ? AND status = ?", userID, models.FriendshipPending)
+		case FriendshipListBlocked:
+			return db.Where("user_id = ? AND status = ?", userID, models.FriendshipBlocked)
+		default:
+			return db.Where("(user_id = ? OR friend_id = ?) AND status = ?", userID, userID, models.FriendshipAccepted)
+		}
+	}
+
+	var total int64
+	if err := fr.mysqlDB.Model(&models.Friendship{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	friendships := []models.Friendship{}
+	err := fr.mysqlDB.Scopes(filter).
+		Preload("User").
+		Preload("Friend").
+		Order("updated_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&friendships).Error
+	return friendships, total, err
+}
+
+// Block memblokir targetID oleh userID. Friendship lain di antara keduanya (pending atau
+// accepted) dihapus lebih dulu dan dikembalikan sebagai removed. Block disimpan satu arah,
+// sehingga dua user bisa saling memblokir.
+func (fr *friendshipRepository) Block(userID, targetID uint) (*models.Friendship, *models.Friendship, error) {
+	block := &models.Friendship{}
+	var removed *models.Friendship
+
+	err := fr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		locked := []uint{}
+		err := tx.Model(&models.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{userID, targetID}).
+			Order("id").
+			Pluck("id", &locked).Error
+		if err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		existing := models.Friendship{}
+		err = tx.Where("((user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)) AND status <> ?",
+			userID, targetID, targetID, userID, models.FriendshipBlocked).
+			First(&existing).Error
+		switch {
+		case err == nil:
+			now := time.Now()
+			err = tx.Model(&existing).Updates(map[string]interface{}{"deleted_at": now, "updated_at": now}).Error
+			if err != nil {
+				return err
+			}
+			removed = &existing
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		err = tx.Where("user_id = ? AND friend_id = ? AND status = ?", userID, targetID, models.FriendshipBlocked).
+			First(block).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		*block = models.Friendship{UserID: userID, FriendID: targetID, Status: models.FriendshipBlocked}
+		return tx.Create(block).Error
+	})
+	return block, removed, err
+}
+
+// FindBlock mengambil block dari userID ke targetID
+func (fr *friendshipRepository) FindBlock(userID, targetID uint) (*models.Friendship, error) {
+	friendship := models.Friendship{}
+	err := fr.mysqlDB.
+		Where("user_id = ? AND friend_id = ? AND status = ?", userID, targetID, models.FriendshipBlocked).
+		First(&friendship).Error
+	return &friendship, err
+}
+
+// Blocked mengecek apakah salah satu dari dua user memblokir yang lain
+func (fr *friendshipRepository) Blocked(userA, userB uint) (bool, error) {
+	var count int64
+	err := fr.mysqlDB.Model(&models.Friendship{}).
+		Where("((user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)) AND status = ?",
+			userA, userB, userB, userA, models.FriendshipBlocked).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BlockedIDs mengambil ID user yang diblokir oleh userID
+func (fr *friendshipRepository) BlockedIDs(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := fr.mysqlDB.Model(&models.Friendship{}).
+		Where("user_id = ? AND status = ?", userID, models.FriendshipBlocked).
+		Pluck("friend_id", &ids).Error
+	return ids, err
+}
+
+// BlockerIDs mengambil ID user yang memblokir userID
+func (fr *friendshipRepository) BlockerIDs(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := fr.mysqlDB.Model(&models.Friendship{}).
+		Where("friend_id = ? AND status = ?", userID, models.FriendshipBlocked).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// FindChangedSince mengambil friendship user (termasuk yang sudah dihapus) yang berubah
+// setelah cursor, beserta profil kedua user. Block oleh user lain tidak ikut, agar user
+// tidak tahu dirinya diblokir.
+func (fr *friendshipRepository) FindChangedSince(userID uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.Friendship, error) {
+	friendships := []models.Friendship{}
+	query := fr.mysqlDB.Unscoped().
+		Preload("User").
+		Preload("Friend").
+		Where("user_id = ? OR (friend_id = ? AND status <> ?)", userID, userID, models.FriendshipBlocked)
+	err := changedSince(query, since, afterID, until, limit).Find(&friendships).Error
+	return friendships, err
+}
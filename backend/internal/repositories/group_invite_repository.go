@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InviteRedeemResult adalah hasil GroupInviteRepository.Redeem
+type InviteRedeemResult int
+
+const (
+	InviteJoined InviteRedeemResult = iota
+	InviteRequested
+	InviteUnusable
+	InviteAlreadyMember
+	InviteAlreadyRequested
+)
+
+type GroupInviteRepository interface {
+	Create(invite *models.GroupInvite) error
+	FindByID(id uint) (*models.GroupInvite, error)
+	FindByToken(token string) (*models.GroupInvite, error)
+	ListForGroup(groupID uint) ([]models.GroupInvite, error)
+	Revoke(id uint, now time.Time) (bool, error)
+	Uses(inviteID uint) ([]models.GroupInviteUse, error)
+	Redeem(inviteID uint, request *models.GroupJoinRequest, now time.Time) (InviteRedeemResult, error)
+}
+
+type groupInviteRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewGroupInviteRepository(mysqlDB *gorm.DB) GroupInviteRepository {
+	return &groupInviteRepository{mysqlDB: mysqlDB}
+}
+
+func (ir *groupInviteRepository) Create(invite *models.GroupInvite) error {
+	return ir.mysqlDB.Omit(clause.Associations).Create(invite).Error
+}
+
+func (ir *groupInviteRepository) FindByID(id uint) (*models.GroupInvite, error) {
+	invite := models.GroupInvite{}
+	err := ir.mysqlDB.First(&invite, id).Error
+	return &invite, err
+}
+
+func (ir *groupInviteRepository) FindByToken(token string) (*models.GroupInvite, error) {
+	invite := models.GroupInvite{}
+	err := ir.mysqlDB.Where("token = ?", token).First(&invite).Error
+	return &invite, err
+}
+
+// ListForGroup mengambil semua invite grup beserta pembuatnya, terbaru lebih dulu
+func (ir *groupInviteRepository) ListForGroup(groupID uint) ([]models.GroupInvite, error) {
+	invites := []models.GroupInvite{}
+	err := ir.mysqlDB.Preload("Creator").
+		Where("group_id = ?", groupID).
+		Order("created_at DESC, id DESC").
+		Find(&invites).Error
+	return invites, err
+}
+
+// Revoke mencabut invite, false jika invite sudah dicabut sebelumnya
+func (ir *groupInviteRepository) Revoke(id uint, now time.Time) (bool, error) {
+	result := ir.mysqlDB.Model(&models.GroupInvite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Uses mengambil semua pemakaian invite beserta user-nya, terbaru lebih dulu
+func (ir *groupInviteRepository) Uses(inviteID uint) ([]models.GroupInviteUse, error) {
+	uses := []models.GroupInviteUse{}
+	err := ir.mysqlDB.Preload("User").
+		Where("invite_id = ?", inviteID).
+		Order("created_at DESC, id DESC").
+		Find(&uses).Error
+	return uses, err
+}
+
+// lockInvite mengunci baris grup lalu baris invite, urutan yang sama dengan
+// GroupJoinRequestRepository.Review agar redeem dan approval bersamaan tidak deadlock
+func lockInvite(tx *gorm.DB, inviteID uint) (*models.GroupInvite, error) {
+	target := models.GroupInvite{}
+	if err := tx.Select("id", "group_id").First(&target, inviteID).Error; err != nil {
+		return nil, err
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&models.Group{}, target.GroupID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	invite := models.GroupInvite{}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invite, inviteID).Error
+	return &invite, err
+}
+
+// Redeem memakai invite untuk request.UserID: langsung menjadi member, atau membuat request
+// sebagai join request pending jika invite butuh persetujuan. Baris grup lalu invite dikunci selama transaksi,
+// sehingga batas pemakaian tetap terjaga dan tidak ada keanggotaan ganda. Setiap pemakaian
+// dicatat pada invite, tetapi uses hanya bertambah saat user benar-benar bergabung; untuk
+// invite dengan persetujuan, uses bertambah saat join request-nya disetujui.
+func (ir *groupInviteRepository) Redeem(inviteID uint, request *models.GroupJoinRequest, now time.Time) (InviteRedeemResult, error) {
+	result := InviteJoined
+
+	err := ir.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		invite, err := lockInvite(tx, inviteID)
+		if err != nil {
+			return err
+		}
+		if !invite.Usable(now) {
+			result = InviteUnusable
+			return nil
+		}
+
+		member, err := isMember(tx, invite.GroupID, request.UserID)
+		if err != nil {
+			return err
+		}
+		if member {
+			result = InviteAlreadyMember
+			return nil
+		}
+
+		status := models.GroupInviteUseJoined
+		if invite.RequiresApproval {
+			request.GroupID = invite.GroupID
+			request.InviteID = &invite.ID
+			created, err := createJoinRequest(tx, request)
+			if err != nil {
+				return err
+			}
+			if !created {
+				result = InviteAlreadyRequested
+				return nil
+			}
+			status = models.GroupInviteUseRequested
+			result = InviteRequested
+		} else {
+			member := models.GroupMember{GroupID: invite.GroupID, UserID: request.UserID, Role: models.GroupRoleMember}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		}
+
+		use := models.GroupInviteUse{InviteID: invite.ID, GroupID: invite.GroupID, UserID: request.UserID, Status: status}
+		if err := tx.Create(&use).Error; err != nil {
+			return err
+		}
+		if status != models.GroupInviteUseJoined {
+			return nil
+		}
+		return tx.Model(invite).Update("uses", gorm.Expr("uses + 1")).Error
+	})
+	return result, err
+}
+
+// inviteJoined menghitung pemakaian invite untuk join request dari invite yang disetujui,
+// di dalam transaksi yang sudah mengunci baris grup
+func inviteJoined(tx *gorm.DB, inviteID, userID uint) error {
+	err := tx.Model(&models.GroupInvite{}).
+		Where("id = ?", inviteID).
+		Update("uses", gorm.Expr("uses + 1")).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.GroupInviteUse{}).
+		Where("invite_id = ? AND user_id = ? AND status = ?", inviteID, userID, models.GroupInviteUseRequested).
+		Update("status", models.GroupInviteUseJoined).Error
+}
@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JoinRequestResult adalah hasil GroupJoinRequestRepository.Create
+type JoinRequestResult int
+
+const (
+	JoinRequestCreated JoinRequestResult = iota
+	JoinRequestAlreadyMember
+	JoinRequestAlreadyPending
+)
+
+// JoinRequestReviewResult adalah hasil GroupJoinRequestRepository.Review
+type JoinRequestReviewResult int
+
+const (
+	JoinRequestReviewed JoinRequestReviewResult = iota
+	JoinRequestNotPending
+	JoinRequestInviteUnusable
+)
+
+type GroupJoinRequestRepository interface {
+	Create(request *models.GroupJoinRequest) (JoinRequestResult, error)
+	FindByID(id uint) (*models.GroupJoinRequest, error)
+	Pending(groupID uint, offset, limit int) ([]models.GroupJoinRequest, int64, error)
+	Review(id, reviewerID uint, status string, now time.Time) (JoinRequestReviewResult, error)
+	Expire(before, now time.Time, limit int) ([]models.GroupJoinRequest, error)
+}
+
+type groupJoinRequestRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewGroupJoinRequestRepository(mysqlDB *gorm.DB) GroupJoinRequestRepository {
+	return &groupJoinRequestRepository{mysqlDB: mysqlDB}
+}
+
+// isMember memeriksa keanggotaan user di dalam transaksi
+func isMember(tx *gorm.DB, groupID, userID uint) (bool, error) {
+	err := tx.Select("id").Where("group_id = ? AND user_id = ?", groupID, userID).
+		First(&models.GroupMember{}).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// createJoinRequest membuat join request pending di dalam transaksi yang sudah mengunci
+// baris grup, false jika user sudah punya join request pending di grup
+func createJoinRequest(tx *gorm.DB, request *models.GroupJoinRequest) (bool, error) {
+	err := tx.Select("id").
+		Where("group_id = ? AND user_id = ? AND status = ?", request.GroupID, request.UserID, models.GroupJoinRequestPending).
+		First(&models.GroupJoinRequest{}).Error
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	request.Status = models.GroupJoinRequestPending
+	return true, tx.Omit(clause.Associations).Create(request).Error
+}
+
+// Create membuat join request pending. Baris grup dikunci selama transaksi, sehingga user
+// yang sudah menjadi anggota atau masih punya request pending tidak membuat request baru.
+func (jr *groupJoinRequestRepository) Create(request *models.GroupJoinRequest) (JoinRequestResult, error) {
+	result := JoinRequestCreated
+
+	err := jr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.Group{}, request.GroupID).Error
+		if err != nil {
+			return err
+		}
+
+		member, err := isMember(tx, request.GroupID, request.UserID)
+		if err != nil {
+			return err
+		}
+		if member {
+			result = JoinRequestAlreadyMember
+			return nil
+		}
+
+		created, err := createJoinRequest(tx, request)
+		if err != nil {
+			return err
+		}
+		if !created {
+			result = JoinRequestAlreadyPending
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (jr *groupJoinRequestRepository) FindByID(id uint) (*models.GroupJoinRequest, error) {
+	request := models.GroupJoinRequest{}
+	err := jr.mysqlDB.Preload("User").First(&request, id).Error
+	return &request, err
+}
+
+// Pending mengambil join request pending di grup beserta user-nya, terlama lebih dulu
+func (jr *groupJoinRequestRepository) Pending(groupID uint, offset, limit int) ([]models.GroupJoinRequest, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		return db.Where("group_id = ? AND status = ?", groupID, models.GroupJoinRequestPending)
+	}
+
+	var total int64
+	if err := jr.mysqlDB.Model(&models.GroupJoinRequest{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	requests := []models.GroupJoinRequest{}
+	err := jr.mysqlDB.Scopes(filter).
+		Preload("User").
+		Order("created_at, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&requests).Error
+	return requests, total, err
+}
+
+// Review menyetujui atau menolak join request yang masih pending. Request yang disetujui
+// menambahkan user sebagai member jika belum menjadi anggota. Request yang berasal dari invite
+// link hanya bisa disetujui selama invite masih bisa dipakai, dan dihitung sebagai pemakaian
+// invite. Baris grup lalu invite dikunci selama transaksi, urutan yang sama dengan Redeem.
+func (jr *groupJoinRequestRepository) Review(id, reviewerID uint, status string, now time.Time) (JoinRequestReviewResult, error) {
+	result := JoinRequestReviewed
+
+	err := jr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		request := models.GroupJoinRequest{}
+		if err := tx.Select("id", "group_id", "user_id", "invite_id").First(&request, id).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.Group{}, request.GroupID).Error
+		if err != nil {
+			return err
+		}
+
+		approved := status == models.GroupJoinRequestApproved
+		member := false
+		if approved {
+			if member, err = isMember(tx, request.GroupID, request.UserID); err != nil {
+				return err
+			}
+		}
+		// pemakaian invite hanya dihitung jika approval benar-benar menambahkan member
+		countInvite := approved && !member && request.InviteID != nil
+		if countInvite {
+			invite := models.GroupInvite{}
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invite, *request.InviteID).Error
+			if err != nil {
+				return err
+			}
+			if !invite.Usable(now) {
+				result = JoinRequestInviteUnusable
+				return nil
+			}
+		}
+
+		updated := tx.Model(&models.GroupJoinRequest{}).
+			Where("id = ? AND status = ?", id, models.GroupJoinRequestPending).
+			Updates(map[string]interface{}{"status": status, "reviewer_id": reviewerID, "reviewed_at": now})
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			result = JoinRequestNotPending
+			return nil
+		}
+		if !approved || member {
+			return nil
+		}
+
+		if err := tx.Create(&models.GroupMember{GroupID: request.GroupID, UserID: request.UserID, Role: models.GroupRoleMember}).Error; err != nil {
+			return err
+		}
+		if !countInvite {
+			return nil
+		}
+		return inviteJoined(tx, *request.InviteID, request.UserID)
+	})
+	return result, err
+}
+
+// Expire menandai paling banyak limit join request yang pending sejak sebelum before
+// sebagai expired, lalu mengembalikannya
+func (jr *groupJoinRequestRepository) Expire(before, now time.Time, limit int) ([]models.GroupJoinRequest, error) {
+	requests := []models.GroupJoinRequest{}
+
+	err := jr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND created_at < ?", models.GroupJoinRequestPending, before).
+			Order("created_at, id").
+			Limit(limit).
+			Find(&requests).Error
+		if err != nil || len(requests) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(requests))
+		for i := range requests {
+			ids[i] = requests[i].ID
+			requests[i].Status = models.GroupJoinRequestExpired
+			requests[i].ReviewedAt = &now
+		}
+		return tx.Model(&models.GroupJoinRequest{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.GroupJoinRequestExpired, "reviewed_at": now}).Error
+	})
+	return requests, err
+}
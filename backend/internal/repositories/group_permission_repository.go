@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GroupPermissionRepository interface {
+	Find(groupID uint) ([]models.GroupPermissionOverride, error)
+	Replace(groupID uint, role string, overrides []models.GroupPermissionOverride) error
+}
+
+type groupPermissionRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewGroupPermissionRepository(mysqlDB *gorm.DB) GroupPermissionRepository {
+	return &groupPermissionRepository{mysqlDB: mysqlDB}
+}
+
+// Find mengambil semua override permission grup
+func (pr *groupPermissionRepository) Find(groupID uint) ([]models.GroupPermissionOverride, error) {
+	overrides := []models.GroupPermissionOverride{}
+	err := pr.mysqlDB.Where("group_id = ?", groupID).Find(&overrides).Error
+	return overrides, err
+}
+
+// Replace mengganti semua override permission satu role di grup
+func (pr *groupPermissionRepository) Replace(groupID uint, role string, overrides []models.GroupPermissionOverride) error {
+	return pr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("group_id = ? AND role = ?", groupID, role).
+			Delete(&models.GroupPermissionOverride{}).Error
+		if err != nil {
+			return err
+		}
+		if len(overrides) == 0 {
+			return nil
+		}
+		return tx.Create(&overrides).Error
+	})
+}
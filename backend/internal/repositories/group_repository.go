@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GroupLeaveResult adalah hasil GroupRepository.Leave
+type GroupLeaveResult struct {
+	NewOwnerID uint // pemilik baru, jika yang keluar adalah pemilik grup
+	Deleted    bool // grup ikut dihapus karena anggota terakhir keluar
+}
+
+type GroupRepository interface {
+	MemberIDs(groupID uint) ([]uint, error)
+	GroupIDsForUser(userID uint) ([]uint, error)
+	MemberIDsOfGroups(groupIDs []uint) ([]uint, error)
+	SharedGroupCounts(userID uint) (map[uint]int, error)
+	FindMembershipsChangedSince(userID uint, groupIDs []uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.GroupMember, error)
+	Create(group *models.Group) error
+	FindByID(id uint) (*models.Group, error)
+	Update(group *models.Group) error
+	Delete(id uint) error
+	FindMember(groupID, userID uint) (*models.GroupMember, error)
+	Members(groupID uint) ([]models.GroupMember, error)
+	AddMembers(groupID uint, userIDs []uint) ([]uint, error)
+	RemoveMember(groupID, userID uint) (bool, error)
+	UpdateMemberRole(groupID, userID uint, role string) (bool, error)
+	Leave(groupID, userID uint) (*GroupLeaveResult, error)
+	TransferOwnership(groupID, fromID, toID uint) (bool, error)
+	ListForUser(userID uint, offset, limit int) ([]models.GroupMember, int64, error)
+	MemberCounts(groupIDs []uint) (map[uint]int64, error)
+}
+
+type groupRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewGroupRepository(mysqlDB *gorm.DB) GroupRepository {
+	return &groupRepository{mysqlDB: mysqlDB}
+}
+
+func (gr *groupRepository) MemberIDs(groupID uint) ([]uint, error) {
+	ids := []uint{}
+	err := gr.mysqlDB.Model(&models.GroupMember{}).
+		Where("group_id = ?", groupID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (gr *groupRepository) GroupIDsForUser(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := gr.mysqlDB.Model(&models.GroupMember{}).
+		Where("user_id = ?", userID).
+		Pluck("group_id", &ids).Error
+	return ids, err
+}
+
+// MemberIDsOfGroups mengambil ID unik semua anggota dari beberapa grup
+func (gr *groupRepository) MemberIDsOfGroups(groupIDs []uint) ([]uint, error) {
+	ids := []uint{}
+	if len(groupIDs) == 0 {
+		return ids, nil
+	}
+	err := gr.mysqlDB.Model(&models.GroupMember{}).
+		Where("group_id IN ?", groupIDs).
+		Distinct().
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// SharedGroupCounts menghitung jumlah grup yang sama untuk setiap user lain yang satu grup
+// dengan userID
+func (gr *groupRepository) SharedGroupCounts(userID uint) (map[uint]int, error) {
+	rows := []struct {
+		UserID uint
+		Shared int
+	}{}
+	groupIDs := gr.mysqlDB.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID)
+	err := gr.mysqlDB.Model(&models.GroupMember{}).
+		Select("user_id, COUNT(*) AS shared").
+		Where("group_id IN (?) AND user_id <> ?", groupIDs, userID).
+		Group("user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Shared
+	}
+	return counts, nil
+}
+
+// FindMembershipsChangedSince mengambil perubahan keanggotaan (bergabung, keluar, ganti role)
+// milik user sendiri dan anggota grup yang sedang diikutinya
+func (gr *groupRepository) FindMembershipsChangedSince(userID uint, groupIDs []uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.GroupMember, error) {
+	members := []models.GroupMember{}
+	query := gr.mysqlDB.Unscoped().Preload("Group").Preload("User")
+	if len(groupIDs) > 0 {
+		query = query.Where("user_id = ? OR group_id IN ?", userID, groupIDs)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := changedSince(query, since, afterID, until, limit).Find(&members).Error
+	return members, err
+}
+
+// Create membuat grup beserta keanggotaan admin untuk pemiliknya
+func (gr *groupRepository) Create(group *models.Group) error {
+	return gr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GroupMember{GroupID: group.ID, UserID: group.OwnerID, Role: models.GroupRoleAdmin}).Error
+	})
+}
+
+func (gr *groupRepository) FindByID(id uint) (*models.Group, error) {
+	group := models.Group{}
+	err := gr.mysqlDB.Preload("Owner").First(&group, id).Error
+	return &group, err
+}
+
+// Update menyimpan info grup yang bisa diubah anggota
+func (gr *groupRepository) Update(group *models.Group) error {
+	return gr.mysqlDB.Model(group).
+		Select("name", "description", "avatar_url", "is_private", "is_open", "join_questions").
+		Updates(group).Error
+}
+
+// Delete menghapus (soft delete) grup beserta semua keanggotaannya. updated_at keanggotaan
+// ikut diubah agar penghapusan terbaca oleh /sync.
+func (gr *groupRepository) Delete(id uint) error {
+	return gr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.GroupMember{}).
+			Where("group_id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": now, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, id).Error
+	})
+}
+
+func (gr *groupRepository) FindMember(groupID, userID uint) (*models.GroupMember, error) {
+	member := models.GroupMember{}
+	err := gr.mysqlDB.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	return &member, err
+}
+
+// Members mengambil anggota grup beserta profilnya, urut dari yang paling lama bergabung
+func (gr *groupRepository) Members(groupID uint) ([]models.GroupMember, error) {
+	members := []models.GroupMember{}
+	err := gr.mysqlDB.Preload("User").
+		Where("group_id = ?", groupID).
+		Order("joined_at, id").
+		Find(&members).Error
+	return members, err
+}
+
+// AddMembers menambahkan user sebagai member grup dan mengembalikan ID yang benar-benar
+// ditambahkan. Baris grup dikunci selama transaksi, sehingga penambahan bersamaan tidak
+// membuat keanggotaan ganda.
+func (gr *groupRepository) AddMembers(groupID uint, userIDs []uint) ([]uint, error) {
+	added := []uint{}
+
+	err := gr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.Group{}, groupID).Error
+		if err != nil {
+			return err
+		}
+
+		existing := []uint{}
+		err = tx.Model(&models.GroupMember{}).
+			Where("group_id = ? AND user_id IN ?", groupID, userIDs).
+			Pluck("user_id", &existing).Error
+		if err != nil {
+			return err
+		}
+
+		skip := make(map[uint]bool, len(userIDs))
+		for _, userID := range existing {
+			skip[userID] = true
+		}
+		members := []models.GroupMember{}
+		for _, userID := range userIDs {
+			if skip[userID] {
+				continue
+			}
+			skip[userID] = true
+			members = append(members, models.GroupMember{GroupID: groupID, UserID: userID, Role: models.GroupRoleMember})
+			added = append(added, userID)
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return tx.Create(&members).Error
+	})
+	return added, err
+}
+
+// RemoveMember menghapus (soft delete) keanggotaan user, false jika user bukan anggota
+func (gr *groupRepository) RemoveMember(groupID, userID uint) (bool, error) {
+	now := time.Now()
+	result := gr.mysqlDB.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Updates(map[string]interface{}{"deleted_at": now, "updated_at": now})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateMemberRole mengubah role anggota, false jika user bukan anggota atau sudah menjadi
+// pemilik grup (role pemilik selalu admin)
+func (gr *groupRepository) UpdateMemberRole(groupID, userID uint, role string) (bool, error) {
+	ownerID := gr.mysqlDB.Model(&models.Group{}).Select("owner_id").Where("id = ?", groupID)
+	result := gr.mysqlDB.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ? AND user_id <> (?)", groupID, userID, ownerID).
+		Updates(map[string]interface{}{"role": role, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Leave mengeluarkan userID dari grup. Jika userID adalah pemilik grup, kepemilikan pindah ke
+// admin yang paling lama bergabung (jika tidak ada admin, ke moderator lalu member yang paling
+// lama bergabung) dan anggota tersebut menjadi admin. Jika tidak ada anggota tersisa, grup
+// ikut dihapus. Baris grup dikunci selama transaksi agar kepemilikan tidak pernah kosong.
+func (gr *groupRepository) Leave(groupID, userID uint) (*GroupLeaveResult, error) {
+	var result *GroupLeaveResult
+	err := gr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = leaveGroup(tx, groupID, userID)
+		return err
+	})
+	return result, err
+}
+
+// leaveGroup menjalankan Leave di dalam transaksi tx
+func leaveGroup(tx *gorm.DB, groupID, userID uint) (*GroupLeaveResult, error) {
+	result := &GroupLeaveResult{}
+	group := models.Group{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "owner_id").
+		First(&group, groupID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	left := tx.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Updates(map[string]interface{}{"deleted_at": now, "updated_at": now})
+	if left.Error != nil {
+		return nil, left.Error
+	}
+	if left.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	successor := models.GroupMember{}
+	err = tx.Where("group_id = ?", groupID).
+		Order("FIELD(role, 'admin', 'moderator', 'member'), joined_at, id").
+		First(&successor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Deleted = true
+		return result, tx.Delete(&models.Group{}, groupID).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	if group.OwnerID != userID {
+		return result, nil
+	}
+
+	result.NewOwnerID = successor.UserID
+	return result, setOwner(tx, groupID, successor.UserID)
+}
+
+// TransferOwnership memindahkan kepemilikan grup dari fromID ke anggota toID, yang sekaligus
+// menjadi admin. false jika fromID sudah bukan pemilik grup.
+func (gr *groupRepository) TransferOwnership(groupID, fromID, toID uint) (bool, error) {
+	transferred := false
+
+	err := gr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		group := models.Group{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "owner_id").
+			First(&group, groupID).Error
+		if err != nil || group.OwnerID != fromID {
+			return err
+		}
+		err = tx.Select("id").Where("group_id = ? AND user_id = ?", groupID, toID).
+			First(&models.GroupMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		transferred = true
+		return setOwner(tx, groupID, toID)
+	})
+	return transferred, err
+}
+
+// setOwner menjadikan anggota userID pemilik sekaligus admin grup di dalam transaksi
+func setOwner(tx *gorm.DB, groupID, userID uint) error {
+	err := tx.Model(&models.Group{}).Where("id = ?", groupID).Update("owner_id", userID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Updates(map[string]interface{}{"role": models.GroupRoleAdmin, "updated_at": time.Now()}).Error
+}
+
+// ListForUser mengambil keanggotaan user di grup yang masih ada beserta grupnya,
+// terbaru bergabung lebih dulu
+func (gr *groupRepository) ListForUser(userID uint, offset, limit int) ([]models.GroupMember, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		groupIDs := gr.mysqlDB.Model(&models.Group{}).Select("id")
+		return db.Where("user_id = ? AND group_id IN (?)", userID, groupIDs)
+	}
+
+	var total int64
+	if err := gr.mysqlDB.Model(&models.GroupMember{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	memberships := []models.GroupMember{}
+	err := gr.mysqlDB.Scopes(filter).
+		Preload("Group.Owner").
+		Order("joined_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&memberships).Error
+	return memberships, total, err
+}
+
+// MemberCounts menghitung jumlah anggota setiap grup
+func (gr *groupRepository) MemberCounts(groupIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return counts, nil
+	}
+
+	rows := []struct {
+		GroupID uint
+		Members int64
+	}{}
+	err := gr.mysqlDB.Model(&models.GroupMember{}).
+		Select("group_id, COUNT(*) AS members").
+		Where("group_id IN ?", groupIDs).
+		Group("group_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.GroupID] = row.Members
+	}
+	return counts, nil
+}
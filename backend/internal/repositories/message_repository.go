@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MessageRepository interface {
+	Create(message *models.ChatMessage) error
+	FindByID(id primitive.ObjectID) (*models.ChatMessage, error)
+	Update(message *models.ChatMessage) error
+	FindPage(conversation *models.Conversation, before primitive.ObjectID, limit int64) ([]models.ChatMessage, error)
+	CountUnread(conversation *models.Conversation, userID uint, since time.Time) (int64, error)
+	Search(userID uint, groupIDs []uint, filter models.MessageSearchFilter) ([]models.MessageSearchResult, int64, error)
+	FindByConversation(conversation *models.Conversation, afterID primitive.ObjectID, limit int64) ([]models.ChatMessage, error)
+	FindChangedSince(userID uint, groupIDs []uint, since time.Time, afterID primitive.ObjectID, until time.Time, limit int64) ([]models.ChatMessage, error)
+	EnsureIndexes() error
+}
+
+type messageRepository struct {
+	mongoDB *mongo.Database
+}
+
+func NewMessageRepository(mongoDB *mongo.Database) MessageRepository {
+	return &messageRepository{mongoDB: mongoDB}
+}
+
+func (mr *messageRepository) collection() *mongo.Collection {
+	return mr.mongoDB.Collection(models.ChatMessage{}.CollectionName())
+}
+
+func (mr *messageRepository) Create(message *models.ChatMessage) error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	if message.ID.IsZero() {
+		message.ID = primitive.NewObjectID()
+	}
+	_, err := mr.collection().InsertOne(ctx, message)
+	return err
+}
+
+func (mr *messageRepository) FindByID(id primitive.ObjectID) (*models.ChatMessage, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	message := models.ChatMessage{}
+	err := mr.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&message)
+	return &message, err
+}
+
+// Update menyimpan perubahan isi/status pesan (edit dan hapus)
+func (mr *messageRepository) Update(message *models.ChatMessage) error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	_, err := mr.collection().UpdateByID(ctx, message.ID, bson.M{"$set": bson.M{
+		"content":     message.Content,
+		"attachments": message.Attachments,
+		"is_edited":   message.IsEdited,
+		"is_deleted":  message.IsDeleted,
+		"updated_at":  message.UpdatedAt,
+		"deleted_at":  message.DeletedAt,
+	}})
+	return err
+}
+
+// FindPage mengambil pesan terbaru sebelum cursor (untuk scroll ke atas), diurutkan dari yang terbaru
+func (mr *messageRepository) FindPage(conversation *models.Conversation, before primitive.ObjectID, limit int64) ([]models.ChatMessage, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := conversationFilter(conversation)
+	if !before.IsZero() {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := mr.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.ChatMessage{}
+	err = cursor.All(ctx, &messages)
+	return messages, err
+}
+
+// conversationFilter menerjemahkan conversation menjadi filter chat_messages.
+// Grup memakai group_id, DM memakai pasangan sender/recipient dua arah.
+func conversationFilter(conversation *models.Conversation) bson.M {
+	if conversation.GroupID != nil {
+		return bson.M{"group_id": *conversation.GroupID}
+	}
+
+	pairs := bson.A{}
+	for _, sender := range conversation.Participants {
+		for _, recipient := range conversation.Participants {
+			if sender != recipient {
+				pairs = append(pairs, bson.M{"sender_id": sender, "recipient_id": recipient})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		// conversation tanpa pasangan participant tidak punya pesan
+		return bson.M{"_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"$or": pairs}
+}
+
+// CountUnread menghitung pesan dari user lain yang dibuat setelah read watermark
+func (mr *messageRepository) CountUnread(conversation *models.Conversation, userID uint, since time.Time) (int64, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := conversationFilter(conversation)
+	filter["sender_id"] = bson.M{"$ne": userID}
+	filter["is_deleted"] = bson.M{"$ne": true}
+	if !since.IsZero() {
+		filter["created_at"] = bson.M{"$gt": since}
+	}
+
+	return mr.collection().CountDocuments(ctx, filter)
+}
+
+// memberScope membatasi pesan ke conversation yang diikuti user:
+// DM di mana user adalah sender/recipient dan grup tempat user menjadi anggota.
+func memberScope(userID uint, groupIDs []uint) bson.M {
+	scope := bson.A{
+		bson.M{"recipient_id": userID},
+		bson.M{"sender_id": userID, "group_id": bson.M{"$exists": false}},
+	}
+	if len(groupIDs) > 0 {
+		scope = append(scope, bson.M{"group_id": bson.M{"$in": groupIDs}})
+	}
+	return bson.M{"$or": scope}
+}
+
+func (mr *messageRepository) Search(userID uint, groupIDs []uint, filter models.MessageSearchFilter) ([]models.MessageSearchResult, int64, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	conditions := bson.A{memberScope(userID, groupIDs), bson.M{"is_deleted": bson.M{"$ne": true}}}
+	if filter.Conversation != nil {
+		conditions = append(conditions, conversationFilter(filter.Conversation))
+	}
+	if filter.SenderID != nil {
+		conditions = append(conditions, bson.M{"sender_id": *filter.SenderID})
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, bson.M{"type": filter.Type})
+	}
+	if filter.HasAttachment {
+		conditions = append(conditions, bson.M{"attachments.0": bson.M{"$exists": true}})
+	}
+	if filter.Since != nil || filter.Until != nil {
+		createdAt := bson.M{}
+		if filter.Since != nil {
+			createdAt["$gte"] = *filter.Since
+		}
+		if filter.Until != nil {
+			createdAt["$lte"] = *filter.Until
+		}
+		conditions = append(conditions, bson.M{"created_at": createdAt})
+	}
+
+	// $text harus berada di level teratas query
+	query := bson.M{
+		"$text": bson.M{"$search": filter.Query},
+		"$and":  conditions,
+	}
+
+	total, err := mr.collection().CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "created_at", Value: -1}}).
+		SetSkip((filter.Page - 1) * filter.Limit).
+		SetLimit(filter.Limit)
+
+	cursor, err := mr.collection().Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := []models.MessageSearchResult{}
+	err = cursor.All(ctx, &results)
+	return results, total, err
+}
+
+// FindByConversation mengambil pesan conversation secara kronologis (keyset pagination berdasarkan _id)
+func (mr *messageRepository) FindByConversation(conversation *models.Conversation, afterID primitive.ObjectID, limit int64) ([]models.ChatMessage, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := conversationFilter(conversation)
+	filter["is_deleted"] = bson.M{"$ne": true}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := mr.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.ChatMessage{}
+	err = cursor.All(ctx, &messages)
+	return messages, err
+}
+
+// FindChangedSince mengambil pesan (baru, diedit, atau dihapus) di conversation user
+// yang berubah setelah cursor, diurutkan dari perubahan terlama
+func (mr *messageRepository) FindChangedSince(userID uint, groupIDs []uint, since time.Time, afterID primitive.ObjectID, until time.Time, limit int64) ([]models.ChatMessage, error) {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	filter := bson.M{"$and": bson.A{memberScope(userID, groupIDs), changedSinceFilter(since, afterID, until)}}
+	cursor, err := mr.collection().Find(ctx, filter, changedSinceOptions(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.ChatMessage{}
+	err = cursor.All(ctx, &messages)
+	return messages, err
+}
+
+// EnsureIndexes membuat index yang dibutuhkan collection chat_messages
+func (mr *messageRepository) EnsureIndexes() error {
+	ctx, cancel := mongoContext()
+	defer cancel()
+
+	_, err := mr.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// default_language "none": tanpa stemming bahasa Inggris karena pesan multi bahasa
+			Keys:    bson.D{{Key: "content", Value: "text"}},
+			Options: options.Index().SetName("content_text").SetDefaultLanguage("none"),
+		},
+		{
+			Keys:    bson.D{{Key: "sender_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("sender_id_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("group_id_created_at"),
+		},
+		{
+			// dipakai /sync untuk mengambil perubahan setelah cursor
+			Keys:    bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("updated_at_id"),
+		},
+	})
+	return err
+}
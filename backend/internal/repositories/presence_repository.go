@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const presenceTrackedKey = "presence:tracked"
+
+// PresenceTransition adalah hasil evaluasi presence seorang user.
+// Previous dan Status sama jika tidak ada perubahan.
+type PresenceTransition struct {
+	Previous   string
+	Status     string
+	LastActive time.Time
+}
+
+func (t PresenceTransition) Changed() bool {
+	return t.Previous != t.Status
+}
+
+// PresenceState adalah status presence user saat ini. LastActive kosong jika
+// aktivitas terakhir sudah tidak tercatat di Redis.
+type PresenceState struct {
+	Status     string
+	LastActive time.Time
+}
+
+// PresenceRepository menyimpan heartbeat setiap device dan status presence user di Redis.
+// Status dihitung ulang secara atomik di setiap perubahan, sehingga transisi hanya
+// terdeteksi sekali walaupun dievaluasi dari banyak instance sekaligus.
+type PresenceRepository interface {
+	Touch(userID uint, deviceID string, ttl time.Duration, active bool, awayAfter time.Duration) (PresenceTransition, error)
+	RemoveDevice(userID uint, deviceID string, awayAfter time.Duration) (PresenceTransition, error)
+	Evaluate(userIDs []uint, awayAfter time.Duration) ([]PresenceTransition, error)
+	TrackedUsers(cursor uint64, count int64) ([]uint, uint64, error)
+	States(userIDs []uint) ([]PresenceState, error)
+}
+
+type presenceRepository struct {
+	redisClient *redis.Client
+}
+
+func NewPresenceRepository(redisClient *redis.Client) PresenceRepository {
+	return &presenceRepository{redisClient: redisClient}
+}
+
+// presenceScript memperbarui heartbeat device (op "touch"/"remove") lalu menghitung status:
+// offline jika tidak ada device yang heartbeat-nya masih berlaku, away jika aktivitas
+// terakhir lebih lama dari awayAfter, selain itu online.
+// Key status memakai format yang sama dengan CacheService.GetUserOnlineStatus.
+var presenceScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local op = ARGV[4]
+if op == "touch" then
+	redis.call("ZADD", KEYS[1], ARGV[6], ARGV[5])
+	redis.call("PEXPIREAT", KEYS[1], ARGV[6])
+	if ARGV[7] == "1" then
+		redis.call("SET", KEYS[2], ARGV[1], "EX", 604800)
+	end
+elseif op == "remove" then
+	redis.call("ZREM", KEYS[1], ARGV[5])
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+
+local previous = redis.call("GET", KEYS[3]) or "offline"
+local lastActive = redis.call("GET", KEYS[2]) or "0"
+local status = "offline"
+if redis.call("ZCARD", KEYS[1]) > 0 then
+	status = "online"
+	if now - tonumber(lastActive) >= tonumber(ARGV[2]) then
+		status = "away"
+	end
+end
+
+if status ~= previous then
+	if status == "offline" then
+		redis.call("DEL", KEYS[3])
+		redis.call("SREM", KEYS[4], ARGV[3])
+	else
+		redis.call("SET", KEYS[3], status)
+		redis.call("SADD", KEYS[4], ARGV[3])
+	end
+end
+return {previous, status, lastActive}
+`)
+
+func (pr *presenceRepository) run(scripter redis.Scripter, userID uint, awayAfter time.Duration, args ...interface{}) *redis.Cmd {
+	keys := []string{
+		fmt.Sprintf("presence:devices:%d", userID),
+		fmt.Sprintf("presence:active:%d", userID),
+		fmt.Sprintf("user:status:%d", userID),
+		presenceTrackedKey,
+	}
+	argv := append([]interface{}{time.Now().UnixMilli(), awayAfter.Milliseconds(), userID}, args...)
+	return presenceScript.Eval(context.Background(), scripter, keys, argv...)
+}
+
+func parseTransition(cmd *redis.Cmd) (PresenceTransition, error) {
+	values, err := cmd.Slice()
+	if err != nil {
+		return PresenceTransition{}, err
+	}
+	if len(values) != 3 {
+		return PresenceTransition{}, fmt.Errorf("unexpected presence script result: %v", values)
+	}
+
+	transition := PresenceTransition{
+		Previous: fmt.Sprint(values[0]),
+		Status:   fmt.Sprint(values[1]),
+	}
+	if ms, _ := strconv.ParseInt(fmt.Sprint(values[2]), 10, 64); ms > 0 {
+		transition.LastActive = time.UnixMilli(ms).UTC()
+	}
+	return transition, nil
+}
+
+// Touch mencatat heartbeat device yang berlaku selama ttl. active berarti user
+// berinteraksi dengan aplikasi sejak heartbeat sebelumnya.
+func (pr *presenceRepository) Touch(userID uint, deviceID string, ttl time.Duration, active bool, awayAfter time.Duration) (PresenceTransition, error) {
+	activeFlag := "0"
+	if active {
+		activeFlag = "1"
+	}
+	expiresAt := time.Now().Add(ttl).UnixMilli()
+	return parseTransition(pr.run(pr.redisClient, userID, awayAfter, "touch", deviceID, expiresAt, activeFlag))
+}
+
+// RemoveDevice menghapus heartbeat device yang koneksinya ditutup
+func (pr *presenceRepository) RemoveDevice(userID uint, deviceID string, awayAfter time.Duration) (PresenceTransition, error) {
+	return parseTransition(pr.run(pr.redisClient, userID, awayAfter, "remove", deviceID))
+}
+
+// Evaluate menghitung ulang status beberapa user sekaligus dalam satu pipeline
+func (pr *presenceRepository) Evaluate(userIDs []uint, awayAfter time.Duration) ([]PresenceTransition, error) {
+	pipe := pr.redisClient.Pipeline()
+	cmds := make([]*redis.Cmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = pr.run(pipe, userID, awayAfter, "check")
+	}
+	if _, err := pipe.Exec(context.Background()); err != nil {
+		return nil, err
+	}
+
+	transitions := make([]PresenceTransition, len(cmds))
+	for i, cmd := range cmds {
+		transition, err := parseTransition(cmd)
+		if err != nil {
+			return nil, err
+		}
+		transitions[i] = transition
+	}
+	return transitions, nil
+}
+
+// TrackedUsers mengambil user yang statusnya tidak offline secara bertahap (SSCAN)
+func (pr *presenceRepository) TrackedUsers(cursor uint64, count int64) ([]uint, uint64, error) {
+	members, next, err := pr.redisClient.SScan(context.Background(), presenceTrackedKey, cursor, "", count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, uint(userID))
+	}
+	return userIDs, next, nil
+}
+
+// States membaca status dan aktivitas terakhir beberapa user dalam satu round trip
+// tanpa mengevaluasi ulang heartbeat (itu tugas sweeper)
+func (pr *presenceRepository) States(userIDs []uint) ([]PresenceState, error) {
+	states := make([]PresenceState, len(userIDs))
+	if len(userIDs) == 0 {
+		return states, nil
+	}
+
+	statusKeys := make([]string, len(userIDs))
+	activeKeys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		statusKeys[i] = fmt.Sprintf("user:status:%d", userID)
+		activeKeys[i] = fmt.Sprintf("presence:active:%d", userID)
+	}
+
+	ctx := context.Background()
+	pipe := pr.redisClient.Pipeline()
+	statusCmd := pipe.MGet(ctx, statusKeys...)
+	activeCmd := pipe.MGet(ctx, activeKeys...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i := range userIDs {
+		states[i].Status = "offline"
+		if status, ok := statusCmd.Val()[i].(string); ok {
+			states[i].Status = status
+		}
+		if value, ok := activeCmd.Val()[i].(string); ok {
+			if ms, _ := strconv.ParseInt(value, 10, 64); ms > 0 {
+				states[i].LastActive = time.UnixMilli(ms).UTC()
+			}
+		}
+	}
+	return states, nil
+}
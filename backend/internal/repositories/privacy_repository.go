@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PrivacyRepository interface {
+	Find(userID uint) (*models.PrivacySettings, error)
+	FindMany(userIDs []uint) (map[uint]models.PrivacySettings, error)
+	Save(settings *models.PrivacySettings) error
+}
+
+type privacyRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func NewPrivacyRepository(mysqlDB *gorm.DB) PrivacyRepository {
+	return &privacyRepository{mysqlDB: mysqlDB}
+}
+
+// Find mengambil pengaturan privasi user, pengaturan default jika belum pernah diubah
+func (pr *privacyRepository) Find(userID uint) (*models.PrivacySettings, error) {
+	settings := models.PrivacySettings{}
+	err := pr.mysqlDB.First(&settings, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = models.DefaultPrivacySettings(userID)
+		return &settings, nil
+	}
+	return &settings, err
+}
+
+// FindMany mengambil pengaturan privasi beberapa user sekaligus, termasuk default
+// untuk user yang belum pernah mengubahnya
+func (pr *privacyRepository) FindMany(userIDs []uint) (map[uint]models.PrivacySettings, error) {
+	result := make(map[uint]models.PrivacySettings, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	rows := []models.PrivacySettings{}
+	if err := pr.mysqlDB.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		result[userID] = models.DefaultPrivacySettings(userID)
+	}
+	for _, row := range rows {
+		result[row.UserID] = row
+	}
+	return result, nil
+}
+
+// Save menyimpan seluruh pengaturan privasi user. Insert mengganti nilai false dengan
+// default kolom, jadi baris default dibuat lebih dulu lalu semua kolom di-update.
+func (pr *privacyRepository) Save(settings *models.PrivacySettings) error {
+	return pr.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		defaults := models.DefaultPrivacySettings(settings.UserID)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaults).Error; err != nil {
+			return err
+		}
+		return tx.Model(settings).Select("*").Updates(settings).Error
+	})
+}
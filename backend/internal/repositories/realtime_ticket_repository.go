@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RealtimeTicketRepository menyimpan ticket sekali pakai untuk membuka koneksi realtime,
+// agar Firebase ID token tidak perlu dikirim lewat query string
+type RealtimeTicketRepository interface {
+	Create(ticket string, userID uint, ttl time.Duration) error
+	Consume(ticket string) (uint, bool, error)
+}
+
+type realtimeTicketRepository struct {
+	redisClient *redis.Client
+}
+
+func NewRealtimeTicketRepository(redisClient *redis.Client) RealtimeTicketRepository {
+	return &realtimeTicketRepository{redisClient: redisClient}
+}
+
+func realtimeTicketKey(ticket string) string {
+	return fmt.Sprintf("realtime:ticket:%s", ticket)
+}
+
+// Create menyimpan ticket milik userID yang kedaluwarsa setelah ttl
+func (rr *realtimeTicketRepository) Create(ticket string, userID uint, ttl time.Duration) error {
+	return rr.redisClient.Set(context.Background(), realtimeTicketKey(ticket), userID, ttl).Err()
+}
+
+// Consume mengambil sekaligus menghapus ticket sehingga ticket hanya bisa dipakai sekali.
+// false berarti ticket tidak ada, sudah dipakai atau sudah kedaluwarsa.
+func (rr *realtimeTicketRepository) Consume(ticket string) (uint, bool, error) {
+	value, err := rr.redisClient.GetDel(context.Background(), realtimeTicketKey(ticket)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(userID), true, nil
+}
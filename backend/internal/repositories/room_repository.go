@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"context"
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// roomTTL membatasi umur room yang ditinggal tanpa leave (misalnya instance mati),
+// diperpanjang setiap kali ada yang join atau mengubah state
+const roomTTL = 12 * time.Hour
+
+// RoomJoinResult adalah hasil RoomRepository.Join
+type RoomJoinResult int
+
+const (
+	RoomJoined RoomJoinResult = iota
+	RoomStarted
+	RoomFull
+	RoomAlreadyOpen
+	RoomNotOpen
+)
+
+// RoomRepository menyimpan room call grup di Redis: data room, participant beserta
+// state medianya, dan room yang sedang diikuti setiap user.
+type RoomRepository interface {
+	Join(room *models.CallRoom, participant models.RoomParticipant, maxParticipants int, create bool) (RoomJoinResult, error)
+	Leave(groupID, userID uint, deviceID string) (bool, *models.CallRoom, error)
+	Close(groupID uint) (*models.CallRoom, error)
+	Find(groupID uint) (*models.CallRoom, error)
+	UpdateParticipant(groupID, userID uint, update func(participant *models.RoomParticipant)) (bool, error)
+	RoomOfUser(userID uint) (uint, error)
+}
+
+type roomRepository struct {
+	redisClient *redis.Client
+}
+
+func NewRoomRepository(redisClient *redis.Client) RoomRepository {
+	return &roomRepository{redisClient: redisClient}
+}
+
+func roomKeys(groupID uint) []string {
+	return []string{fmt.Sprintf("room:%d", groupID), fmt.Sprintf("room:%d:participants", groupID)}
+}
+
+func roomUserKey(userID uint) string {
+	return fmt.Sprintf("room:user:%d", userID)
+}
+
+// joinRoomScript memasukkan participant ke room secara atomik sambil menjaga batas jumlah
+// participant. Dengan mode "create" room harus belum terbuka, selain itu harus sudah terbuka.
+// User yang join ulang (misalnya dari device lain) tidak dihitung dua kali.
+var joinRoomScript = redis.NewScript(`
+local open = redis.call("EXISTS", KEYS[1]) == 1
+if ARGV[5] == "create" and open then
+	return 3
+end
+if ARGV[5] ~= "create" and not open then
+	return 4
+end
+if redis.call("HEXISTS", KEYS[2], ARGV[2]) == 0 and redis.call("HLEN", KEYS[2]) >= tonumber(ARGV[4]) then
+	return 2
+end
+local result = 0
+if not open then
+	redis.call("SET", KEYS[1], ARGV[1])
+	result = 1
+end
+redis.call("HSET", KEYS[2], ARGV[2], ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[6])
+redis.call("PEXPIRE", KEYS[2], ARGV[6])
+redis.call("SET", KEYS[3], ARGV[7], "PX", ARGV[6])
+return result
+`)
+
+// leaveRoomScript mengeluarkan participant (hanya jika device-nya cocok, jika diisi).
+// Room ditutup saat participant terakhir keluar, datanya dikembalikan untuk ringkasan.
+var leaveRoomScript = redis.NewScript(`
+local participant = redis.call("HGET", KEYS[2], ARGV[1])
+if not participant then
+	return {-1, ""}
+end
+if ARGV[2] ~= "" and cjson.decode(participant)["device_id"] ~= ARGV[2] then
+	return {-1, ""}
+end
+redis.call("HDEL", KEYS[2], ARGV[1])
+if redis.call("GET", KEYS[3]) == ARGV[3] then
+	redis.call("DEL", KEYS[3])
+end
+local remaining = redis.call("HLEN", KEYS[2])
+if remaining > 0 then
+	return {remaining, ""}
+end
+local room = redis.call("GET", KEYS[1]) or ""
+redis.call("DEL", KEYS[1])
+return {0, room}
+`)
+
+// closeRoomScript menghapus room beserta penanda room setiap participant (KEYS[3:]) yang
+// masih menunjuk ke grup ini
+var closeRoomScript = redis.NewScript(`
+for i = 3, #KEYS do
+	if redis.call("GET", KEYS[i]) == ARGV[1] then
+		redis.call("DEL", KEYS[i])
+	end
+end
+return redis.call("DEL", KEYS[1], KEYS[2])
+`)
+
+// Join memasukkan participant ke room grup. Jika room belum terbuka, room dibuat dari data room.
+func (rr *roomRepository) Join(room *models.CallRoom, participant models.RoomParticipant, maxParticipants int, create bool) (RoomJoinResult, error) {
+	meta := *room
+	meta.Participants = nil
+	roomData, err := json.Marshal(&meta)
+	if err != nil {
+		return 0, err
+	}
+	participantData, err := json.Marshal(&participant)
+	if err != nil {
+		return 0, err
+	}
+
+	mode := "join"
+	if create {
+		mode = "create"
+	}
+	groupID := strconv.FormatUint(uint64(room.GroupID), 10)
+	keys := append(roomKeys(room.GroupID), roomUserKey(participant.UserID))
+	result, err := joinRoomScript.Run(context.Background(), rr.redisClient, keys,
+		roomData, participant.UserID, participantData, maxParticipants, mode, roomTTL.Milliseconds(), groupID,
+	).Int()
+	return RoomJoinResult(result), err
+}
+
+// Leave mengeluarkan user dari room grup. deviceID kosong berarti device mana pun.
+// Mengembalikan apakah user tadinya participant, dan data room jika room ikut ditutup.
+func (rr *roomRepository) Leave(groupID, userID uint, deviceID string) (bool, *models.CallRoom, error) {
+	keys := append(roomKeys(groupID), roomUserKey(userID))
+	result, err := leaveRoomScript.Run(context.Background(), rr.redisClient, keys,
+		userID, deviceID, strconv.FormatUint(uint64(groupID), 10),
+	).Slice()
+	if err != nil {
+		return false, nil, err
+	}
+
+	remaining, _ := result[0].(int64)
+	data, _ := result[1].(string)
+	if remaining < 0 {
+		return false, nil, nil
+	}
+	if remaining > 0 || data == "" {
+		return true, nil, nil
+	}
+
+	room := &models.CallRoom{}
+	if err := json.Unmarshal([]byte(data), room); err != nil {
+		return true, nil, err
+	}
+	return true, room, nil
+}
+
+// Close menutup room grup tanpa menunggu participant keluar, mengembalikan room beserta
+// participant-nya saat ditutup, redis.Nil jika tidak ada room
+func (rr *roomRepository) Close(groupID uint) (*models.CallRoom, error) {
+	room, err := rr.Find(groupID)
+	if err != nil {
+		return nil, err
+	}
+	keys := roomKeys(groupID)
+	for _, participant := range room.Participants {
+		keys = append(keys, roomUserKey(participant.UserID))
+	}
+	err = closeRoomScript.Run(context.Background(), rr.redisClient, keys, strconv.FormatUint(uint64(groupID), 10)).Err()
+	return room, err
+}
+
+// Find mengambil room grup beserta participant-nya (urut waktu join), redis.Nil jika tidak ada room
+func (rr *roomRepository) Find(groupID uint) (*models.CallRoom, error) {
+	ctx := context.Background()
+	keys := roomKeys(groupID)
+
+	pipe := rr.redisClient.Pipeline()
+	roomCmd := pipe.Get(ctx, keys[0])
+	participantsCmd := pipe.HGetAll(ctx, keys[1])
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	room := &models.CallRoom{}
+	if err := json.Unmarshal([]byte(roomCmd.Val()), room); err != nil {
+		return nil, err
+	}
+	room.Participants = make([]models.RoomParticipant, 0, len(participantsCmd.Val()))
+	for _, data := range participantsCmd.Val() {
+		participant := models.RoomParticipant{}
+		if err := json.Unmarshal([]byte(data), &participant); err != nil {
+			return nil, err
+		}
+		room.Participants = append(room.Participants, participant)
+	}
+	sort.Slice(room.Participants, func(i, j int) bool {
+		return room.Participants[i].JoinedAt.Before(room.Participants[j].JoinedAt)
+	})
+	return room, nil
+}
+
+// UpdateParticipant mengubah state participant secara optimistic (WATCH),
+// mengembalikan false jika user bukan participant room
+func (rr *roomRepository) UpdateParticipant(groupID, userID uint, update func(participant *models.RoomParticipant)) (bool, error) {
+	ctx := context.Background()
+	keys := roomKeys(groupID)
+	field := strconv.FormatUint(uint64(userID), 10)
+
+	for attempt := 0; attempt < callUpdateAttempts; attempt++ {
+		err := rr.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.HGet(ctx, keys[1], field).Bytes()
+			if err != nil {
+				return err
+			}
+			participant := models.RoomParticipant{}
+			if err := json.Unmarshal(data, &participant); err != nil {
+				return err
+			}
+			update(&participant)
+			if data, err = json.Marshal(&participant); err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, keys[1], field, data)
+				pipe.Expire(ctx, keys[0], roomTTL)
+				pipe.Expire(ctx, keys[1], roomTTL)
+				return nil
+			})
+			return err
+		}, keys[1])
+
+		switch {
+		case errors.Is(err, redis.TxFailedErr):
+			continue
+		case errors.Is(err, redis.Nil):
+			return false, nil
+		case err != nil:
+			return false, err
+		}
+		return true, nil
+	}
+	return false, redis.TxFailedErr
+}
+
+// RoomOfUser mengembalikan ID grup dari room yang sedang diikuti user, 0 jika tidak ada
+func (rr *roomRepository) RoomOfUser(userID uint) (uint, error) {
+	groupID, err := rr.redisClient.Get(context.Background(), roomUserKey(userID)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return uint(groupID), err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// suggestionSentinel menandai cache yang sudah dibangun walaupun belum ada kandidat,
+// skornya negatif sehingga tidak pernah terbaca sebagai kandidat
+const suggestionSentinel = "0"
+
+// SuggestionChange adalah perubahan skor satu kandidat di cache saran pertemanan user
+type SuggestionChange struct {
+	UserID      uint
+	CandidateID uint
+	Delta       float64
+}
+
+// SuggestionRepository menyimpan cache saran pertemanan per user di Redis sebagai sorted set
+// kandidat dengan skor peringkatnya.
+type SuggestionRepository interface {
+	Save(userID uint, scores map[uint]float64, ttl time.Duration) error
+	Top(userID uint, count int) ([]uint, []float64, bool, error)
+	Apply(changes []SuggestionChange) error
+}
+
+type suggestionRepository struct {
+	redisClient *redis.Client
+}
+
+func NewSuggestionRepository(redisClient *redis.Client) SuggestionRepository {
+	return &suggestionRepository{redisClient: redisClient}
+}
+
+func suggestionKey(userID uint) string {
+	return fmt.Sprintf("suggestions:%d", userID)
+}
+
+// applySuggestionsScript mengubah skor kandidat hanya di cache yang sudah ada (cache yang
+// belum ada akan dibangun lengkap saat dibaca), lalu membuang kandidat yang skornya habis
+var applySuggestionsScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	if redis.call("EXISTS", key) == 1 then
+		local score = tonumber(redis.call("ZINCRBY", key, ARGV[i * 2], ARGV[i * 2 - 1]))
+		if score <= 0 then
+			redis.call("ZREM", key, ARGV[i * 2 - 1])
+		end
+	end
+end
+return 0
+`)
+
+// Save mengganti seluruh cache saran user
+func (sr *suggestionRepository) Save(userID uint, scores map[uint]float64, ttl time.Duration) error {
+	ctx := context.Background()
+	key := suggestionKey(userID)
+
+	members := make([]*redis.Z, 0, len(scores)+1)
+	members = append(members, &redis.Z{Score: -1, Member: suggestionSentinel})
+	for candidateID, score := range scores {
+		members = append(members, &redis.Z{Score: score, Member: candidateID})
+	}
+
+	_, err := sr.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.ZAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// Top mengambil count kandidat dengan skor tertinggi, false jika cache user belum ada
+func (sr *suggestionRepository) Top(userID uint, count int) ([]uint, []float64, bool, error) {
+	ctx := context.Background()
+	key := suggestionKey(userID)
+
+	pipe := sr.redisClient.Pipeline()
+	existsCmd := pipe.Exists(ctx, key)
+	topCmd := pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: int64(count)})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, nil, false, err
+	}
+	if existsCmd.Val() == 0 {
+		return nil, nil, false, nil
+	}
+
+	ids := make([]uint, 0, len(topCmd.Val()))
+	scores := make([]float64, 0, len(topCmd.Val()))
+	for _, z := range topCmd.Val() {
+		id, err := strconv.ParseUint(z.Member.(string), 10, 64)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		ids = append(ids, uint(id))
+		scores = append(scores, z.Score)
+	}
+	return ids, scores, true, nil
+}
+
+// Apply menerapkan perubahan skor ke cache user yang sudah ada dalam satu round trip
+func (sr *suggestionRepository) Apply(changes []SuggestionChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(changes))
+	args := make([]interface{}, 0, len(changes)*2)
+	for _, change := range changes {
+		keys = append(keys, suggestionKey(change.UserID))
+		args = append(args, change.CandidateID, change.Delta)
+	}
+	return applySuggestionsScript.Run(context.Background(), sr.redisClient, keys, args...).Err()
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TypingRepository menyimpan state "sedang mengetik" yang bersifat sementara di Redis.
+// Setiap conversation punya sorted set dengan member user_id dan score waktu kedaluwarsa.
+type TypingRepository interface {
+	SetTyping(conversationID string, userID uint, ttl time.Duration) (time.Time, error)
+	ClearTyping(conversationID string, userID uint) (bool, error)
+	ListTyping(conversationID string) (map[uint]time.Time, error)
+}
+
+type typingRepository struct {
+	redisClient *redis.Client
+}
+
+func NewTypingRepository(redisClient *redis.Client) TypingRepository {
+	return &typingRepository{redisClient: redisClient}
+}
+
+func typingKey(conversationID string) string {
+	return fmt.Sprintf("typing:%s", conversationID)
+}
+
+// SetTyping menandai user sedang mengetik dan mengembalikan waktu kedaluwarsa sebelumnya
+// (zero time jika sebelumnya user tidak sedang mengetik)
+func (tr *typingRepository) SetTyping(conversationID string, userID uint, ttl time.Duration) (time.Time, error) {
+	ctx := context.Background()
+	key := typingKey(conversationID)
+	now := time.Now()
+	member := strconv.FormatUint(uint64(userID), 10)
+
+	pipe := tr.redisClient.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	previous := pipe.ZScore(ctx, key, member)
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.Add(ttl).UnixMilli()), Member: member})
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return time.Time{}, err
+	}
+	if previous.Err() == redis.Nil {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(int64(previous.Val())), nil
+}
+
+// ClearTyping menghapus state mengetik, mengembalikan true jika user memang sedang mengetik
+func (tr *typingRepository) ClearTyping(conversationID string, userID uint) (bool, error) {
+	removed, err := tr.redisClient.ZRem(context.Background(), typingKey(conversationID), strconv.FormatUint(uint64(userID), 10)).Result()
+	return removed > 0, err
+}
+
+// ListTyping mengembalikan user yang masih mengetik beserta waktu kedaluwarsanya
+func (tr *typingRepository) ListTyping(conversationID string) (map[uint]time.Time, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	entries, err := tr.redisClient.ZRangeByScoreWithScores(context.Background(), typingKey(conversationID), &redis.ZRangeBy{
+		Min: "(" + now,
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	typers := make(map[uint]time.Time, len(entries))
+	for _, entry := range entries {
+		userID, err := strconv.ParseUint(fmt.Sprint(entry.Member), 10, 64)
+		if err != nil {
+			continue
+		}
+		typers[uint(userID)] = time.UnixMilli(int64(entry.Score)).UTC()
+	}
+	return typers, nil
+}
@@ -3,6 +3,9 @@ package repositories
 import (
 	"context"
 	"echo-chat-app-backend/internal/models"
+	"errors"
+	"fmt"
+	"time"
 
 	"firebase.google.com/go/v4/auth"
 	"gorm.io/gorm"
@@ -10,8 +13,22 @@ import (
 
 type UserRepository interface {
 	Me(uid string) (*models.User, error)
+	FindByID(id uint) (*models.User, error)
+	FindByIDs(ids []uint) ([]models.User, error)
 	SearchUserByUsername(username string) (*models.User, error)
+	FindDiscoverableByEmailHashes(hashes []string) ([]models.User, error)
+	UpdateDiscoverable(id uint, discoverable bool) error
 	UpdateProfile(uid, name, username, avatar_url string) (*models.User, error)
+	UpdatePresence(id uint, status string, lastSeen time.Time) error
+	FindChangedSince(ids []uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.User, error)
+	Delete(id uint) (*AccountDeletion, error)
+	DeleteFirebaseUser(uid string) error
+}
+
+// AccountDeletion adalah hasil UserRepository.Delete
+type AccountDeletion struct {
+	Groups      map[uint]*GroupLeaveResult // hasil keluar dari setiap grup, per group_id
+	Friendships []models.Friendship        // pertemanan, permintaan dan blokir yang ikut dihapus
 }
 
 type userRepository struct {
@@ -29,12 +46,45 @@ func (ur *userRepository) Me(uid string) (*models.User, error) {
 	return &user, err
 }
 
+func (ur *userRepository) FindByID(id uint) (*models.User, error) {
+	user := models.User{}
+	err := ur.mysqlDB.First(&user, id).Error
+	return &user, err
+}
+
+func (ur *userRepository) FindByIDs(ids []uint) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := ur.mysqlDB.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// SearchUserByUsername mencari user berdasarkan username, kecuali user yang mematikan
+// pencarian lewat pengaturan privasi
 func (ur *userRepository) SearchUserByUsername(username string) (*models.User, error) {
 	user := models.User{}
-	err := ur.mysqlDB.Where("username = ?", username).First(&user).Error
+	hidden := ur.mysqlDB.Model(&models.PrivacySettings{}).Select("user_id").Where("searchable = ?", false)
+	err := ur.mysqlDB.Where("username = ? AND id NOT IN (?)", username, hidden).First(&user).Error
 	return &user, err
 }
 
+// FindDiscoverableByEmailHashes mengambil user yang bisa ditemukan lewat kontak
+// berdasarkan hash email
+func (ur *userRepository) FindDiscoverableByEmailHashes(hashes []string) ([]models.User, error) {
+	users := []models.User{}
+	if len(hashes) == 0 {
+		return users, nil
+	}
+	err := ur.mysqlDB.Where("email_hash IN ? AND discoverable = ?", hashes, true).Find(&users).Error
+	return users, err
+}
+
+func (ur *userRepository) UpdateDiscoverable(id uint, discoverable bool) error {
+	return ur.mysqlDB.Model(&models.User{}).Where("id = ?", id).Update("discoverable", discoverable).Error
+}
+
 func (ur *userRepository) UpdateProfile(uid, name, username, avatar_url string) (*models.User, error) {
 	ctx := context.Background()
 	user := models.User{}
@@ -65,3 +115,101 @@ func (ur *userRepository) UpdateProfile(uid, name, username, avatar_url string)
 	err = ur.mysqlDB.Where("firebase_uid = ?", uid).First(&user).Error
 	return &user, err
 }
+
+// UpdatePresence menyimpan status presence terakhir user (dipanggil saat status berubah).
+// UpdateColumns tidak mengubah updated_at, perubahan presence bukan perubahan profil untuk /sync.
+func (ur *userRepository) UpdatePresence(id uint, status string, lastSeen time.Time) error {
+	return ur.mysqlDB.Model(&models.User{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"status": status, "last_seen": lastSeen}).Error
+}
+
+// FindChangedSince mengambil profil dari daftar user yang berubah setelah cursor
+func (ur *userRepository) FindChangedSince(ids []uint, since time.Time, afterID uint, until time.Time, limit int) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := changedSince(ur.mysqlDB.Where("id IN ?", ids), since, afterID, until, limit).Find(&users).Error
+	return users, err
+}
+
+// Delete menghapus akun user di mysql dalam satu transaksi: user keluar dari semua grupnya
+// (dengan perpindahan kepemilikan seperti GroupRepository.Leave), semua pertemanan dan join
+// request pending-nya dihapus, lalu user di-soft delete. Kolom unik dan data profil
+// dikosongkan agar email dan akun Firebase yang sama bisa mendaftar lagi.
+func (ur *userRepository) Delete(id uint) (*AccountDeletion, error) {
+	deletion := &AccountDeletion{Groups: map[uint]*GroupLeaveResult{}}
+
+	err := ur.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		// kunci grup dengan urutan yang sama agar tidak deadlock dengan transaksi lain
+		groupIDs := []uint{}
+		err := tx.Model(&models.GroupMember{}).
+			Where("user_id = ?", id).
+			Order("group_id").
+			Pluck("group_id", &groupIDs).Error
+		if err != nil {
+			return err
+		}
+		for _, groupID := range groupIDs {
+			result, err := leaveGroup(tx, groupID, id)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			deletion.Groups[groupID] = result
+		}
+
+		err = tx.Where("user_id = ? OR friend_id = ?", id, id).Find(&deletion.Friendships).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		err = tx.Model(&models.Friendship{}).
+			Where("user_id = ? OR friend_id = ?", id, id).
+			Updates(map[string]interface{}{"deleted_at": now, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.GroupJoinRequest{}).
+			Where("user_id = ? AND status = ?", id, models.GroupJoinRequestPending).
+			Updates(map[string]interface{}{"status": models.GroupJoinRequestExpired, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		deleted := tx.Model(&models.User{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"firebase_uid": fmt.Sprintf("deleted:%d", id),
+				"email":        fmt.Sprintf("deleted:%d", id),
+				"email_hash":   "",
+				"full_name":    "",
+				"username":     "",
+				"avatar_url":   "",
+				"discoverable": false,
+				"status":       "offline",
+				"deleted_at":   now,
+				"updated_at":   now,
+			})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// DeleteFirebaseUser menghapus akun user di firebase, dipanggil setelah Delete berhasil
+func (ur *userRepository) DeleteFirebaseUser(uid string) error {
+	return ur.authClient.DeleteUser(context.Background(), uid)
+}
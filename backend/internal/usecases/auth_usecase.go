@@ -6,16 +6,18 @@ import (
 )
 
 type AuthUseCase struct {
-	authRepo repositories.AuthRepository
+	authRepo  repositories.AuthRepository
+	emailSalt string
 }
 
-func NewAuthUseCase(authRepo repositories.AuthRepository) *AuthUseCase {
+func NewAuthUseCase(authRepo repositories.AuthRepository, emailSalt string) *AuthUseCase {
 	return &AuthUseCase{
-		authRepo: authRepo,
+		authRepo:  authRepo,
+		emailSalt: emailSalt,
 	}
 }
 
 func (au *AuthUseCase) SyncUser(uid, email, name, avatarURL string) (*models.User, error) {
 	// Panggil metode dari authRepo untuk menyinkronkan data pengguna
-	return au.authRepo.SyncUser(uid, email, name, avatarURL)
+	return au.authRepo.SyncUser(uid, email, HashEmail(au.emailSalt, email), name, avatarURL)
 }
@@ -0,0 +1,322 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	callTimeoutBatchSize = 100
+
+	// maxSignalSize membatasi ukuran SDP atau ICE candidate yang direlay
+	maxSignalSize = 16 << 10
+)
+
+var callSignalTypes = map[string]bool{models.CallSignalOffer: true, models.CallSignalAnswer: true, models.CallSignalICE: true}
+
+type CallConfig struct {
+	RingTimeout time.Duration // call yang tidak dijawab selama ini menjadi missed
+	MaxDuration time.Duration // call yang berjalan lebih lama dari ini diakhiri server
+}
+
+type CallUseCase struct {
+	callRepo       repositories.CallRepository
+	friendshipRepo repositories.FriendshipRepository
+	messageUseCase *MessageUseCase
+	publisher      EventPublisher
+	config         CallConfig
+}
+
+func NewCallUseCase(callRepo repositories.CallRepository, friendshipRepo repositories.FriendshipRepository, messageUseCase *MessageUseCase, publisher EventPublisher, config CallConfig) *CallUseCase {
+	return &CallUseCase{
+		callRepo:       callRepo,
+		friendshipRepo: friendshipRepo,
+		messageUseCase: messageUseCase,
+		publisher:      publisher,
+		config:         config,
+	}
+}
+
+// Start membuat call baru dari device caller ke teman, semua device callee akan berdering
+func (cu *CallUseCase) Start(userID uint, deviceID string, calleeID uint, media string) (*models.Call, error) {
+	if deviceID == "" || len(deviceID) > 64 {
+		return nil, fmt.Errorf("%w: device_id is required", ErrInvalidInput)
+	}
+	if media == "" {
+		media = models.CallMediaAudio
+	}
+	if media != models.CallMediaAudio && media != models.CallMediaVideo {
+		return nil, fmt.Errorf("%w: media must be audio or video", ErrInvalidInput)
+	}
+	if calleeID == userID {
+		return nil, fmt.Errorf("%w: cannot call yourself", ErrInvalidInput)
+	}
+
+	friendIDs, err := cu.friendshipRepo.FriendIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsID(friendIDs, calleeID) {
+		return nil, ErrNotFriends
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	call := &models.Call{
+		ID:           id,
+		CallerID:     userID,
+		CalleeID:     calleeID,
+		Media:        media,
+		Status:       models.CallStatusRinging,
+		CallerDevice: deviceID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(cu.config.RingTimeout),
+	}
+	created, err := cu.callRepo.Create(call)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrBusy
+	}
+
+	cu.publish(call)
+	return call, nil
+}
+
+// Get mengembalikan call yang diikuti user
+func (cu *CallUseCase) Get(userID uint, callID string) (*models.Call, error) {
+	call, err := cu.callRepo.Find(callID)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if call.CallerID != userID && call.CalleeID != userID {
+		return nil, ErrNotFound
+	}
+	return call, nil
+}
+
+// Accept menjawab call dari device callee, device lain milik callee berhenti berdering
+func (cu *CallUseCase) Accept(userID uint, deviceID, callID string) (*models.Call, error) {
+	if deviceID == "" || len(deviceID) > 64 {
+		return nil, fmt.Errorf("%w: device_id is required", ErrInvalidInput)
+	}
+
+	call, err := cu.update(userID, callID, func(call *models.Call) error {
+		if call.CalleeID != userID {
+			return ErrNotParticipant
+		}
+		if call.Status != models.CallStatusRinging {
+			return fmt.Errorf("%w: call is no longer ringing", ErrConflict)
+		}
+
+		now := time.Now().UTC()
+		call.Status = models.CallStatusAccepted
+		call.CalleeDevice = deviceID
+		call.AcceptedAt = &now
+		call.ExpiresAt = now.Add(cu.config.MaxDuration)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cu.publish(call)
+	return call, nil
+}
+
+// Decline menolak call yang masih berdering
+func (cu *CallUseCase) Decline(userID uint, callID string) (*models.Call, error) {
+	call, err := cu.update(userID, callID, func(call *models.Call) error {
+		if call.CalleeID != userID {
+			return ErrNotParticipant
+		}
+		if call.Status != models.CallStatusRinging {
+			return fmt.Errorf("%w: call is no longer ringing", ErrConflict)
+		}
+		finishCall(call, models.CallStatusDeclined, userID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cu.finish(call)
+	return call, nil
+}
+
+// End mengakhiri call oleh salah satu participant. Caller yang membatalkan call sebelum
+// dijawab membuat call menjadi missed, callee yang menutup call yang berdering berarti menolak.
+func (cu *CallUseCase) End(userID uint, callID string) (*models.Call, error) {
+	call, err := cu.update(userID, callID, func(call *models.Call) error {
+		if call.Finished() {
+			return fmt.Errorf("%w: call has already finished", ErrConflict)
+		}
+
+		switch {
+		case call.Status == models.CallStatusAccepted:
+			finishCall(call, models.CallStatusEnded, userID)
+		case userID == call.CallerID:
+			finishCall(call, models.CallStatusMissed, userID)
+		default:
+			finishCall(call, models.CallStatusDeclined, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cu.finish(call)
+	return call, nil
+}
+
+// Signal merelay SDP offer/answer atau ICE candidate ke participant lain. Setelah call
+// dijawab, hanya dua device yang terlibat yang boleh bertukar signal.
+func (cu *CallUseCase) Signal(userID uint, deviceID, callID, signalType string, payload json.RawMessage) error {
+	if !callSignalTypes[signalType] {
+		return fmt.Errorf("%w: signal type must be offer, answer or ice", ErrInvalidInput)
+	}
+	if len(payload) == 0 || len(payload) > maxSignalSize {
+		return fmt.Errorf("%w: signal payload is empty or too large", ErrInvalidInput)
+	}
+
+	call, err := cu.Get(userID, callID)
+	if err != nil {
+		return err
+	}
+	if call.Finished() {
+		return fmt.Errorf("%w: call has already finished", ErrConflict)
+	}
+
+	receiverID, toDevice := call.CalleeID, call.CalleeDevice
+	fromDevice := call.CallerDevice
+	if userID == call.CalleeID {
+		receiverID, toDevice = call.CallerID, call.CallerDevice
+		fromDevice = call.CalleeDevice
+	}
+	if fromDevice != "" && fromDevice != deviceID {
+		return fmt.Errorf("%w: call is active on another device", ErrConflict)
+	}
+
+	cu.publisher.PublishToUsers([]uint{receiverID}, models.NewEvent(models.EventCallSignal, models.CallSignalEvent{
+		CallID:     call.ID,
+		FromUserID: userID,
+		FromDevice: deviceID,
+		ToDevice:   toDevice,
+		Type:       signalType,
+		Payload:    payload,
+	}))
+	return nil
+}
+
+// ExpireCalls mengakhiri call yang melewati deadline: call yang masih berdering menjadi
+// missed dan call yang berjalan terlalu lama diakhiri. Mengembalikan jumlah call yang diakhiri.
+func (cu *CallUseCase) ExpireCalls() (int, error) {
+	ids, err := cu.callRepo.Expired(time.Now().UTC(), callTimeoutBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		call, err := cu.callRepo.Update(id, func(call *models.Call) error {
+			now := time.Now().UTC()
+			// instance lain mungkin sudah memproses atau memperpanjang call ini
+			if call.Finished() || call.ExpiresAt.After(now) {
+				return errCallUnchanged
+			}
+
+			if call.Status == models.CallStatusRinging {
+				finishCall(call, models.CallStatusMissed, 0)
+			} else {
+				// durasi dihitung sampai batas maksimal, bukan sampai sweep berjalan
+				endedAt := call.ExpiresAt
+				finishCall(call, models.CallStatusEnded, 0)
+				call.EndedAt = &endedAt
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errCallUnchanged):
+			continue
+		case errors.Is(err, redis.Nil):
+			if err := cu.callRepo.RemoveDeadline(id); err != nil {
+				log.Printf("Failed to remove deadline of call %s: %v", id, err)
+			}
+			continue
+		case err != nil:
+			return expired, err
+		}
+
+		cu.finish(call)
+		expired++
+	}
+	return expired, nil
+}
+
+// StartTimeouts menjalankan ExpireCalls secara berkala di background
+func (cu *CallUseCase) StartTimeouts(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := cu.ExpireCalls(); err != nil {
+				log.Printf("Call timeout check failed: %v", err)
+			}
+		}
+	}()
+}
+
+var errCallUnchanged = errors.New("call unchanged")
+
+// update menjalankan perubahan status call milik user dan memetakan error Redis ke error usecase
+func (cu *CallUseCase) update(userID uint, callID string, update func(call *models.Call) error) (*models.Call, error) {
+	call, err := cu.callRepo.Update(callID, func(call *models.Call) error {
+		if call.CallerID != userID && call.CalleeID != userID {
+			return ErrNotFound
+		}
+		return update(call)
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return call, err
+}
+
+func finishCall(call *models.Call, status string, endedBy uint) {
+	now := time.Now().UTC()
+	call.Status = status
+	call.EndedAt = &now
+	call.EndedBy = endedBy
+}
+
+// publish mengirim status call terbaru ke semua device kedua participant
+func (cu *CallUseCase) publish(call *models.Call) {
+	cu.publisher.PublishToUsers([]uint{call.CallerID, call.CalleeID}, models.NewEvent(models.EventCall, models.CallEvent{
+		Call: *call,
+	}))
+}
+
+// finish mengirim status akhir call lalu menulis ringkasannya ke DM
+func (cu *CallUseCase) finish(call *models.Call) {
+	cu.publish(call)
+	if _, err := cu.messageUseCase.RecordCall(call); err != nil {
+		log.Printf("Failed to record call %s: %v", call.ID, err)
+	}
+}
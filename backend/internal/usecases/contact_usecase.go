@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ContactConfig struct {
+	Salt       string        // salt publik yang dipakai client dan server untuk hash email
+	MaxBatch   int           // jumlah hash maksimal per request
+	RateLimit  int64         // jumlah request discovery per user dalam RateWindow
+	RateWindow time.Duration // dibatasi agar daftar email tidak bisa dienumerasi
+}
+
+type ContactUseCase struct {
+	userRepo       repositories.UserRepository
+	friendshipRepo repositories.FriendshipRepository
+	privacyUseCase *PrivacyUseCase
+	config         ContactConfig
+}
+
+func NewContactUseCase(userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyUseCase *PrivacyUseCase, config ContactConfig) *ContactUseCase {
+	return &ContactUseCase{
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		privacyUseCase: privacyUseCase,
+		config:         config,
+	}
+}
+
+// HashEmail menghitung hash email untuk contact discovery: hex SHA-256 dari salt diikuti
+// email yang sudah di-trim dan lowercase. Client menghitung hash yang sama dari buku alamat.
+func HashEmail(salt, email string) string {
+	sum := sha256.Sum256([]byte(salt + strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Config mengembalikan parameter hash dan batas yang perlu diketahui client
+func (cu *ContactUseCase) Config() ContactConfig {
+	return cu.config
+}
+
+// Discover mencocokkan hash email dari buku alamat user dengan user terdaftar yang bersedia
+// ditemukan. Hash yang diunggah hanya dipakai untuk query ini dan tidak disimpan.
+func (cu *ContactUseCase) Discover(userID uint, hashes []string) ([]models.ContactMatch, error) {
+	if len(hashes) == 0 || len(hashes) > cu.config.MaxBatch {
+		return nil, fmt.Errorf("%w: send between 1 and %d hashes", ErrInvalidInput, cu.config.MaxBatch)
+	}
+
+	unique := make([]string, 0, len(hashes))
+	seen := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		hash = strings.ToLower(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("%w: hashes must be hex encoded SHA-256", ErrInvalidInput)
+		}
+		if !seen[hash] {
+			seen[hash] = true
+			unique = append(unique, hash)
+		}
+	}
+
+	allowed, err := config.Cache.AllowRate(context.Background(), fmt.Sprintf("contacts:%d", userID), cu.config.RateLimit, cu.config.RateWindow)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrRateLimited
+	}
+
+	users, err := cu.userRepo.FindDiscoverableByEmailHashes(unique)
+	if err != nil {
+		return nil, err
+	}
+	blockerIDs, err := cu.friendshipRepo.BlockerIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.privacyUseCase.FilterPresence(userID, users); err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.ContactMatch, 0, len(users))
+	for _, user := range users {
+		if user.ID == userID || containsID(blockerIDs, user.ID) {
+			continue
+		}
+		matches = append(matches, models.ContactMatch{Hash: user.EmailHash, User: user})
+	}
+	return matches, nil
+}
+
+// SetDiscoverable mengatur apakah user bisa ditemukan lewat contact discovery
+func (cu *ContactUseCase) SetDiscoverable(userID uint, discoverable bool) error {
+	return cu.userRepo.UpdateDiscoverable(userID, discoverable)
+}
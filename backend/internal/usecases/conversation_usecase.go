@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+type ConversationUseCase struct {
+	conversationRepo repositories.ConversationRepository
+	userRepo         repositories.UserRepository
+	friendshipRepo   repositories.FriendshipRepository
+	privacyUseCase   *PrivacyUseCase
+}
+
+func NewConversationUseCase(conversationRepo repositories.ConversationRepository, userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyUseCase *PrivacyUseCase) *ConversationUseCase {
+	return &ConversationUseCase{
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+		friendshipRepo:   friendshipRepo,
+		privacyUseCase:   privacyUseCase,
+	}
+}
+
+// GetOrCreateDM mengembalikan satu-satunya conversation DM antara dua user,
+// kecuali salah satunya memblokir yang lain
+func (cu *ConversationUseCase) GetOrCreateDM(userID, otherUserID uint) (*models.Conversation, error) {
+	if userID == otherUserID {
+		return nil, fmt.Errorf("%w: cannot start a conversation with yourself", ErrInvalidInput)
+	}
+
+	if _, err := cu.userRepo.FindByID(otherUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	blocked, err := cu.friendshipRepo.Blocked(userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
+	// conversation yang sudah ada tetap bisa dibuka, conversation baru mengikuti
+	// pengaturan direct_messages lawan bicara seperti saat mengirim pesan
+	conversation, err := cu.conversationRepo.FindDM(userID, otherUserID)
+	if err == nil {
+		return conversation, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if err := cu.privacyUseCase.CanMessage(userID, otherUserID); err != nil {
+		return nil, err
+	}
+	return cu.conversationRepo.FindOrCreateDM(userID, otherUserID)
+}
+
+// checkDMBlock mengembalikan ErrBlocked jika conversation adalah DM dan salah satu
+// participant memblokir yang lain
+func checkDMBlock(friendshipRepo repositories.FriendshipRepository, conversation *models.Conversation, userID uint) error {
+	if conversation.GroupID != nil {
+		return nil
+	}
+	for _, participantID := range conversation.Participants {
+		if participantID == userID {
+			continue
+		}
+		blocked, err := friendshipRepo.Blocked(userID, participantID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return ErrBlocked
+		}
+	}
+	return nil
+}
+
+// findMemberConversation memastikan conversation ada dan user termasuk participant. Untuk
+// conversation grup, akses diperiksa lewat GroupPermissionUseCase.Authorize dengan permission
+// (kosong berarti cukup menjadi anggota).
+func findMemberConversation(conversationRepo repositories.ConversationRepository, permissionUseCase *GroupPermissionUseCase, userID uint, conversationID, permission string) (*models.Conversation, error) {
+	id, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	conversation, err := conversationRepo.FindByID(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation.GroupID != nil {
+		_, _, err := permissionUseCase.Authorize(*conversation.GroupID, userID, permission)
+		if errors.Is(err, ErrNotMember) || errors.Is(err, ErrNotFound) {
+			return nil, ErrNotParticipant
+		}
+		if err != nil {
+			return nil, err
+		}
+		return conversation, nil
+	}
+	if !containsID(conversation.Participants, userID) {
+		return nil, ErrNotParticipant
+	}
+	return conversation, nil
+}
+
+// conversationMembers mengembalikan participant DM atau anggota grup dari MySQL
+func conversationMembers(groupRepo repositories.GroupRepository, conversation *models.Conversation) ([]uint, error) {
+	if conversation.GroupID != nil {
+		return groupRepo.MemberIDs(*conversation.GroupID)
+	}
+	return conversation.Participants, nil
+}
@@ -0,0 +1,19 @@
+package usecases
+
+import "errors"
+
+// Error yang bisa dipetakan controller ke status HTTP selain 500
+var (
+	ErrInvalidID      = errors.New("invalid id")
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrNotFound       = errors.New("not found")
+	ErrNotParticipant = errors.New("user is not a participant of this conversation")
+	ErrNotFriends     = errors.New("users are not friends")
+	ErrNotMember      = errors.New("user is not a member of this group")
+	ErrForbidden      = errors.New("permission denied")
+	ErrConflict       = errors.New("conflict")
+	ErrBusy           = errors.New("user is already in a call")
+	ErrBlocked        = errors.New("user is blocked")
+	ErrRestricted     = errors.New("not allowed by the user's privacy settings")
+	ErrRateLimited    = errors.New("too many requests, slow down")
+)
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+func exportTitle(conversation *models.Conversation) string {
+	if conversation.GroupID != nil {
+		return fmt.Sprintf("Group conversation %d", *conversation.GroupID)
+	}
+	return "Direct conversation"
+}
+
+func renderExportJSON(w io.Writer, conversation *models.Conversation, messages []models.ExportedMessage) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"conversation": conversation,
+		"exported_at":  time.Now().UTC(),
+		"messages":     messages,
+	})
+}
+
+func renderExportText(w io.Writer, conversation *models.Conversation, messages []models.ExportedMessage) error {
+	if _, err := fmt.Fprintf(w, "%s\nExported at %s\n\n", exportTitle(conversation), time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		edited := ""
+		if message.IsEdited {
+			edited = " (edited)"
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %s: %s%s\n", message.CreatedAt.UTC().Format("2006-01-02 15:04:05"), message.SenderName, message.Content, edited); err != nil {
+			return err
+		}
+
+		for i, attachment := range message.Attachments {
+			location := attachment.URL
+			if i < len(message.AttachmentFiles) && message.AttachmentFiles[i] != "" {
+				location = message.AttachmentFiles[i]
+			}
+			if _, err := fmt.Fprintf(w, "    📎 %s (%s)\n", attachment.FileName, location); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exportHTMLTemplate menghasilkan satu halaman HTML tanpa asset eksternal
+var exportHTMLTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"time":  func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+	"lines": func(s string) []string { return strings.Split(s, "\n") },
+	"file": func(message models.ExportedMessage, i int) string {
+		if i < len(message.AttachmentFiles) && message.AttachmentFiles[i] != "" {
+			return message.AttachmentFiles[i]
+		}
+		return message.Attachments[i].URL
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; background: #f4f5f7; margin: 0; padding: 24px; color: #1f2328; }
+.container { max-width: 760px; margin: 0 auto; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+.meta { color: #6e7781; font-size: 13px; margin-bottom: 24px; }
+.message { background: #fff; border-radius: 8px; padding: 10px 14px; margin-bottom: 8px; box-shadow: 0 1px 2px rgba(0,0,0,.06); }
+.sender { font-weight: 600; font-size: 14px; }
+.time { color: #6e7781; font-size: 12px; margin-left: 8px; }
+.content { margin-top: 4px; font-size: 15px; }
+.attachment { display: block; margin-top: 6px; font-size: 13px; }
+</style>
+</head>
+<body>
+<div class="container">
+<h1>{{.Title}}</h1>
+<div class="meta">Exported at {{time .ExportedAt}} UTC · {{len .Messages}} messages</div>
+{{range $message := .Messages}}<div class="message">
+<span class="sender">{{$message.SenderName}}</span><span class="time">{{time $message.CreatedAt}}{{if $message.IsEdited}} · edited{{end}}</span>
+<div class="content">{{range $i, $line := lines $message.Content}}{{if $i}}<br>{{end}}{{$line}}{{end}}</div>
+{{range $i, $attachment := $message.Attachments}}<a class="attachment" href="{{file $message $i}}">📎 {{$attachment.FileName}}</a>
+{{end}}</div>
+{{end}}</div>
+</body>
+</html>
+`))
+
+func renderExportHTML(w io.Writer, conversation *models.Conversation, messages []models.ExportedMessage) error {
+	return exportHTMLTemplate.Execute(w, map[string]interface{}{
+		"Title":      exportTitle(conversation),
+		"ExportedAt": time.Now().UTC(),
+		"Messages":   messages,
+	})
+}
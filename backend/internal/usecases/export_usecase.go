@@ -0,0 +1,516 @@
+package usecases
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	exportBatchSize = 500
+
+	// worker memperbarui heartbeat tiap exportHeartbeatInterval, job milik worker yang
+	// heartbeat-nya hilang lebih dari exportHeartbeatTTL dikembalikan ke antrian
+	exportHeartbeatInterval = 10 * time.Second
+	exportHeartbeatTTL      = 30 * time.Second
+
+	// job yang terus terputus (misalnya instance crash saat memprosesnya) ditandai gagal
+	maxExportAttempts = 3
+)
+
+// ExportConfig mengatur masa simpan dan batas ukuran export
+type ExportConfig struct {
+	Workers            int
+	Retention          time.Duration // berapa lama job dan file zip disimpan
+	LinkTTL            time.Duration // masa berlaku link download
+	MaxAttachmentBytes int64
+}
+
+type ExportUseCase struct {
+	exportRepo        repositories.ExportRepository
+	messageRepo       repositories.MessageRepository
+	conversationRepo  repositories.ConversationRepository
+	groupRepo         repositories.GroupRepository
+	userRepo          repositories.UserRepository
+	permissionUseCase *GroupPermissionUseCase
+	config            ExportConfig
+	httpClient        *http.Client
+}
+
+func NewExportUseCase(exportRepo repositories.ExportRepository, messageRepo repositories.MessageRepository, conversationRepo repositories.ConversationRepository, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, permissionUseCase *GroupPermissionUseCase, config ExportConfig) *ExportUseCase {
+	return &ExportUseCase{
+		exportRepo:        exportRepo,
+		messageRepo:       messageRepo,
+		conversationRepo:  conversationRepo,
+		groupRepo:         groupRepo,
+		userRepo:          userRepo,
+		permissionUseCase: permissionUseCase,
+		config:            config,
+		httpClient:        newAttachmentClient(),
+	}
+}
+
+// newAttachmentClient membuat http client yang menolak alamat internal,
+// karena URL lampiran berasal dari user (mencegah SSRF).
+func newAttachmentClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return fmt.Errorf("refusing to fetch attachment from %s", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{Proxy: nil, DialContext: dialer.DialContext},
+	}
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateExport membuat job export baru dan memasukkannya ke antrian
+func (eu *ExportUseCase) CreateExport(userID uint, conversationID, format string) (*models.ExportJob, error) {
+	switch format {
+	case models.ExportFormatJSON, models.ExportFormatHTML, models.ExportFormatText:
+	default:
+		return nil, fmt.Errorf("%w: format must be json, html or text", ErrInvalidInput)
+	}
+
+	if _, err := findMemberConversation(eu.conversationRepo, eu.permissionUseCase, userID, conversationID, ""); err != nil {
+		return nil, err
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &models.ExportJob{
+		ID:             id,
+		UserID:         userID,
+		ConversationID: conversationID,
+		Format:         format,
+		Status:         models.ExportStatusPending,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(eu.config.Retention),
+	}
+	if err := eu.exportRepo.SaveJob(job, eu.config.Retention); err != nil {
+		return nil, err
+	}
+	if err := eu.exportRepo.Enqueue(job.ID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob mengembalikan status job milik user, beserta token download jika sudah selesai
+func (eu *ExportUseCase) GetJob(userID uint, jobID string) (*models.ExportJob, string, error) {
+	job, err := eu.exportRepo.FindJob(jobID)
+	if errors.Is(err, redis.Nil) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if job.UserID != userID {
+		return nil, "", ErrNotFound
+	}
+	if job.Status != models.ExportStatusCompleted {
+		return job, "", nil
+	}
+
+	token, err := eu.exportRepo.FindJobDownloadToken(job.ID)
+	if errors.Is(err, redis.Nil) {
+		// link lama sudah kedaluwarsa tetapi file masih disimpan, buat link baru
+		token, err = eu.issueDownloadToken(job.ID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return job, token, nil
+}
+
+// issueDownloadToken membuat token download untuk job yang sudah selesai
+func (eu *ExportUseCase) issueDownloadToken(jobID string) (string, error) {
+	token, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	if err := eu.exportRepo.SaveDownloadToken(token, jobID, eu.config.LinkTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// OpenDownload membuka file zip berdasarkan token download yang masih berlaku
+func (eu *ExportUseCase) OpenDownload(token string) (io.ReadCloser, int64, string, error) {
+	jobID, err := eu.exportRepo.FindDownloadToken(token)
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	job, err := eu.exportRepo.FindJob(jobID)
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if job.FileID == nil {
+		return nil, 0, "", ErrNotFound
+	}
+
+	file, size, err := eu.exportRepo.OpenFile(*job.FileID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	filename := fmt.Sprintf("conversation-%s-%s.zip", job.ConversationID, job.CreatedAt.Format("20060102-150405"))
+	return file, size, filename, nil
+}
+
+// StartWorkers menjalankan worker yang memproses antrian export di background.
+// Job yang diambil worker tetap tercatat di list processing miliknya sampai selesai,
+// dan dikembalikan ke antrian oleh instance lain jika instance ini mati.
+func (eu *ExportUseCase) StartWorkers() {
+	instanceID, err := randomToken(8)
+	if err != nil {
+		log.Printf("Failed to start export workers: %v", err)
+		return
+	}
+
+	workerIDs := make([]string, eu.config.Workers)
+	for i := range workerIDs {
+		workerIDs[i] = fmt.Sprintf("%s:%d", instanceID, i)
+	}
+	if err := eu.exportRepo.Heartbeat(workerIDs, exportHeartbeatTTL); err != nil {
+		log.Printf("Failed to register export workers: %v", err)
+	}
+	go eu.heartbeat(workerIDs)
+
+	for _, workerID := range workerIDs {
+		go func(workerID string) {
+			for {
+				jobID, err := eu.exportRepo.Dequeue(workerID, 5*time.Second)
+				if errors.Is(err, redis.Nil) {
+					continue
+				}
+				if err != nil {
+					log.Printf("Failed to dequeue export job: %v", err)
+					time.Sleep(time.Second)
+					continue
+				}
+				eu.process(jobID)
+				if err := eu.exportRepo.Ack(workerID, jobID); err != nil {
+					log.Printf("Failed to ack export job %s: %v", jobID, err)
+				}
+			}
+		}(workerID)
+	}
+}
+
+// heartbeat menjaga worker instance ini tetap terdaftar hidup dan mengembalikan
+// job milik worker instance lain yang sudah mati ke antrian
+func (eu *ExportUseCase) heartbeat(workerIDs []string) {
+	ticker := time.NewTicker(exportHeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := eu.exportRepo.Heartbeat(workerIDs, exportHeartbeatTTL); err != nil {
+			log.Printf("Failed to refresh export worker heartbeat: %v", err)
+			continue
+		}
+		reclaimed, err := eu.exportRepo.ReclaimStale()
+		if err != nil {
+			log.Printf("Failed to reclaim export jobs: %v", err)
+			continue
+		}
+		if reclaimed > 0 {
+			log.Printf("Reclaimed %d export jobs from stopped workers", reclaimed)
+		}
+	}
+}
+
+// StartCleanup menghapus file export yang kedaluwarsa secara berkala
+func (eu *ExportUseCase) StartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			deleted, err := eu.exportRepo.DeleteExpiredFiles(time.Now().UTC())
+			if err != nil {
+				log.Printf("Export cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Export cleanup deleted %d files", deleted)
+			}
+		}
+	}()
+}
+
+func (eu *ExportUseCase) process(jobID string) {
+	job, err := eu.exportRepo.FindJob(jobID)
+	if err != nil {
+		log.Printf("Export job %s not found: %v", jobID, err)
+		return
+	}
+
+	// job yang dikembalikan ke antrian bisa saja sudah selesai sebelum worker lamanya mati
+	if job.Status == models.ExportStatusCompleted || job.Status == models.ExportStatusFailed {
+		return
+	}
+
+	ttl := time.Until(job.ExpiresAt)
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	job.Attempts++
+	if job.Attempts > maxExportAttempts {
+		job.Status = models.ExportStatusFailed
+		job.Error = "export was interrupted too many times"
+		if err := eu.exportRepo.SaveJob(job, ttl); err != nil {
+			log.Printf("Failed to update export job %s: %v", jobID, err)
+		}
+		return
+	}
+	job.Status = models.ExportStatusProcessing
+	if err := eu.exportRepo.SaveJob(job, ttl); err != nil {
+		log.Printf("Failed to update export job %s: %v", jobID, err)
+		return
+	}
+
+	err = eu.build(job)
+	if err == nil {
+		// link download dibuat sekali saat job selesai, polling status memakai link yang sama
+		_, err = eu.issueDownloadToken(job.ID)
+	}
+	if err != nil {
+		log.Printf("Export job %s failed: %v", jobID, err)
+		job.Status = models.ExportStatusFailed
+		job.Error = err.Error()
+	} else {
+		now := time.Now().UTC()
+		job.Status = models.ExportStatusCompleted
+		job.CompletedAt = &now
+	}
+
+	if err := eu.exportRepo.SaveJob(job, ttl); err != nil {
+		log.Printf("Failed to update export job %s: %v", jobID, err)
+	}
+}
+
+// build merender seluruh riwayat pesan ke zip dan menguploadnya ke GridFS
+func (eu *ExportUseCase) build(job *models.ExportJob) error {
+	// membership dicek ulang karena user bisa saja sudah keluar dari grup
+	conversation, err := findMemberConversation(eu.conversationRepo, eu.permissionUseCase, job.UserID, job.ConversationID, "")
+	if err != nil {
+		return err
+	}
+
+	messages, err := eu.loadMessages(conversation)
+	if err != nil {
+		return err
+	}
+	job.MessageCount = len(messages)
+
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	eu.bundleAttachments(archive, messages)
+
+	var render func(io.Writer, *models.Conversation, []models.ExportedMessage) error
+	var name string
+	switch job.Format {
+	case models.ExportFormatJSON:
+		render, name = renderExportJSON, "conversation.json"
+	case models.ExportFormatHTML:
+		render, name = renderExportHTML, "conversation.html"
+	default:
+		render, name = renderExportText, "conversation.txt"
+	}
+
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := render(writer, conversation, messages); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	fileID, err := eu.exportRepo.UploadFile(job.ID+".zip", tmp, job.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	job.FileID = &fileID
+	job.FileSize = size
+	return nil
+}
+
+// loadMessages mengambil semua pesan dan mengisi nama pengirim dari MySQL
+func (eu *ExportUseCase) loadMessages(conversation *models.Conversation) ([]models.ExportedMessage, error) {
+	messages := []models.ExportedMessage{}
+	senders := map[uint]string{}
+	lastID := primitive.NilObjectID
+
+	for {
+		batch, err := eu.messageRepo.FindByConversation(conversation, lastID, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, message := range batch {
+			senders[message.SenderID] = ""
+			messages = append(messages, models.ExportedMessage{ChatMessage: message})
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+
+	ids := make([]uint, 0, len(senders))
+	for id := range senders {
+		ids = append(ids, id)
+	}
+	users, err := eu.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		senders[user.ID] = displayName(&user)
+	}
+
+	for i := range messages {
+		messages[i].SenderName = senders[messages[i].SenderID]
+		if messages[i].SenderName == "" {
+			messages[i].SenderName = fmt.Sprintf("User %d", messages[i].SenderID)
+		}
+	}
+	return messages, nil
+}
+
+func displayName(user *models.User) string {
+	if user.FullName != "" {
+		return user.FullName
+	}
+	if user.Username != "" {
+		return user.Username
+	}
+	return user.Email
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// bundleAttachments mengunduh lampiran ke folder attachments/ di dalam zip.
+// Lampiran yang gagal diunduh dilewati, link aslinya tetap ada di hasil export.
+func (eu *ExportUseCase) bundleAttachments(archive *zip.Writer, messages []models.ExportedMessage) {
+	for i := range messages {
+		message := &messages[i]
+		if len(message.Attachments) == 0 {
+			continue
+		}
+
+		message.AttachmentFiles = make([]string, len(message.Attachments))
+		for j, attachment := range message.Attachments {
+			filename := unsafeFileChars.ReplaceAllString(path.Base(attachment.FileName), "_")
+			if filename == "" || filename == "." || filename == "_" {
+				filename = "file"
+			}
+			name := fmt.Sprintf("attachments/%s_%d_%s", message.ID.Hex(), j, filename)
+
+			if err := eu.downloadAttachment(archive, name, attachment.URL); err != nil {
+				log.Printf("Skipping attachment %s: %v", attachment.URL, err)
+				continue
+			}
+			message.AttachmentFiles[j] = name
+		}
+	}
+}
+
+func (eu *ExportUseCase) downloadAttachment(archive *zip.Writer, name, url string) error {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return fmt.Errorf("unsupported url")
+	}
+
+	resp, err := eu.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > eu.config.MaxAttachmentBytes {
+		return fmt.Errorf("attachment too large")
+	}
+
+	// baca ke memori dulu agar entry zip tidak setengah jadi jika melebihi batas
+	data, err := io.ReadAll(io.LimitReader(resp.Body, eu.config.MaxAttachmentBytes+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > eu.config.MaxAttachmentBytes {
+		return fmt.Errorf("attachment too large")
+	}
+
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
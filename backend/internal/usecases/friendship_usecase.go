@@ -0,0 +1,245 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FriendshipUseCase struct {
+	friendshipRepo    repositories.FriendshipRepository
+	suggestionUseCase *SuggestionUseCase
+	publisher         EventPublisher
+}
+
+func NewFriendshipUseCase(friendshipRepo repositories.FriendshipRepository, suggestionUseCase *SuggestionUseCase, publisher EventPublisher) *FriendshipUseCase {
+	return &FriendshipUseCase{
+		friendshipRepo:    friendshipRepo,
+		suggestionUseCase: suggestionUseCase,
+		publisher:         publisher,
+	}
+}
+
+// SendRequest mengirim permintaan pertemanan ke user lain. Jika user tersebut sudah lebih dulu
+// mengirim permintaan ke kita, permintaannya langsung diterima.
+func (fu *FriendshipUseCase) SendRequest(userID, friendID uint) (*models.Friendship, error) {
+	if friendID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidInput)
+	}
+	if friendID == userID {
+		return nil, fmt.Errorf("%w: cannot send a friend request to yourself", ErrInvalidInput)
+	}
+
+	friendship, created, err := fu.friendshipRepo.Request(userID, friendID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return fu.publish(friendship.ID, models.FriendshipActionRequested)
+	}
+
+	switch {
+	case friendship.Status == models.FriendshipPending && friendship.FriendID == userID:
+		return fu.accept(friendship)
+	case friendship.Status == models.FriendshipPending:
+		return nil, fmt.Errorf("%w: friend request already sent", ErrConflict)
+	case friendship.Status == models.FriendshipAccepted:
+		return nil, fmt.Errorf("%w: already friends", ErrConflict)
+	case friendship.UserID == userID:
+		return nil, fmt.Errorf("%w: unblock this user first", ErrBlocked)
+	default:
+		// user yang diblokir tidak boleh tahu bahwa dirinya diblokir
+		return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+}
+
+// incoming mengambil permintaan pertemanan pending yang ditujukan ke user
+func (fu *FriendshipUseCase) incoming(userID, id uint) (*models.Friendship, error) {
+	friendship, err := fu.friendshipRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && friendship.FriendID != userID) {
+		return nil, fmt.Errorf("%w: friend request not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if friendship.Status != models.FriendshipPending {
+		return nil, fmt.Errorf("%w: friend request is no longer pending", ErrConflict)
+	}
+	return friendship, nil
+}
+
+// Accept menerima permintaan pertemanan, hanya bisa dilakukan penerima permintaan
+func (fu *FriendshipUseCase) Accept(userID, id uint) (*models.Friendship, error) {
+	friendship, err := fu.incoming(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return fu.accept(friendship)
+}
+
+func (fu *FriendshipUseCase) accept(friendship *models.Friendship) (*models.Friendship, error) {
+	now := time.Now().UTC()
+	updated, err := fu.friendshipRepo.UpdateStatus(friendship.ID, models.FriendshipPending, models.FriendshipAccepted, &now)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: friend request is no longer pending", ErrConflict)
+	}
+	fu.suggestionUseCase.FriendshipChanged(friendship.UserID, friendship.FriendID, true)
+	return fu.publish(friendship.ID, models.FriendshipActionAccepted)
+}
+
+// Decline menolak permintaan pertemanan, hanya bisa dilakukan penerima permintaan
+func (fu *FriendshipUseCase) Decline(userID, id uint) error {
+	friendship, err := fu.incoming(userID, id)
+	if err != nil {
+		return err
+	}
+	return fu.remove(friendship, models.FriendshipPending, models.FriendshipActionDeclined)
+}
+
+// Cancel membatalkan permintaan pertemanan, hanya bisa dilakukan pengirim permintaan
+func (fu *FriendshipUseCase) Cancel(userID, id uint) error {
+	friendship, err := fu.friendshipRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && friendship.UserID != userID) {
+		return fmt.Errorf("%w: friend request not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if friendship.Status != models.FriendshipPending {
+		return fmt.Errorf("%w: friend request is no longer pending", ErrConflict)
+	}
+	return fu.remove(friendship, models.FriendshipPending, models.FriendshipActionCancelled)
+}
+
+// Unfriend menghapus pertemanan dengan user lain
+func (fu *FriendshipUseCase) Unfriend(userID, friendID uint) error {
+	friendship, err := fu.friendshipRepo.FindBetween(userID, friendID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && friendship.Status != models.FriendshipAccepted) {
+		return ErrNotFriends
+	}
+	if err != nil {
+		return err
+	}
+	return fu.remove(friendship, models.FriendshipAccepted, models.FriendshipActionRemoved)
+}
+
+// remove menghapus friendship selama statusnya masih status, lalu memberi tahu kedua user
+func (fu *FriendshipUseCase) remove(friendship *models.Friendship, status, action string) error {
+	deleted, err := fu.friendshipRepo.Delete(friendship.ID, status)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: friendship has already changed", ErrConflict)
+	}
+	if status == models.FriendshipAccepted {
+		fu.suggestionUseCase.FriendshipChanged(friendship.UserID, friendship.FriendID, false)
+	}
+
+	fu.publisher.PublishToUsers([]uint{friendship.UserID, friendship.FriendID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+		Action:     action,
+		Friendship: *friendship,
+	}))
+	return nil
+}
+
+// publish memuat ulang friendship beserta profil kedua user lalu mengirimnya ke keduanya
+func (fu *FriendshipUseCase) publish(id uint, action string) (*models.Friendship, error) {
+	friendship, err := fu.friendshipRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fu.publisher.PublishToUsers([]uint{friendship.UserID, friendship.FriendID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+		Action:     action,
+		Friendship: *friendship,
+	}))
+	return friendship, nil
+}
+
+// Block memblokir user lain. Pertemanan atau permintaan pertemanan di antara keduanya ikut
+// dihapus, dan user yang diblokir hanya menerima event penghapusan tersebut.
+func (fu *FriendshipUseCase) Block(userID, targetID uint) (*models.Friendship, error) {
+	if targetID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidInput)
+	}
+	if targetID == userID {
+		return nil, fmt.Errorf("%w: cannot block yourself", ErrInvalidInput)
+	}
+
+	block, removed, err := fu.friendshipRepo.Block(userID, targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if removed != nil {
+		if removed.Status == models.FriendshipAccepted {
+			fu.suggestionUseCase.FriendshipChanged(removed.UserID, removed.FriendID, false)
+		}
+		action := models.FriendshipActionRemoved
+		if removed.Status == models.FriendshipPending && removed.UserID == userID {
+			action = models.FriendshipActionCancelled
+		} else if removed.Status == models.FriendshipPending {
+			action = models.FriendshipActionDeclined
+		}
+		fu.publisher.PublishToUsers([]uint{removed.UserID, removed.FriendID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+			Action:     action,
+			Friendship: *removed,
+		}))
+	}
+	fu.publisher.PublishToUsers([]uint{userID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+		Action:     models.FriendshipActionBlocked,
+		Friendship: *block,
+	}))
+	return block, nil
+}
+
+// Unblock membuka blokir user lain. Pertemanan sebelumnya tidak dikembalikan.
+func (fu *FriendshipUseCase) Unblock(userID, targetID uint) error {
+	block, err := fu.friendshipRepo.FindBlock(userID, targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: user is not blocked", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	deleted, err := fu.friendshipRepo.Delete(block.ID, models.FriendshipBlocked)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: user is not blocked", ErrNotFound)
+	}
+
+	fu.publisher.PublishToUsers([]uint{userID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+		Action:     models.FriendshipActionUnblocked,
+		Friendship: *block,
+	}))
+	return nil
+}
+
+// List mengambil daftar teman (accepted), permintaan masuk (incoming), permintaan
+// keluar (outgoing), atau user yang diblokir (blocked) milik user
+func (fu *FriendshipUseCase) List(userID uint, kind string, page, limit int) ([]models.Friendship, int64, error) {
+	switch kind {
+	case repositories.FriendshipListAccepted, repositories.FriendshipListIncoming, repositories.FriendshipListOutgoing, repositories.FriendshipListBlocked:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown friend list", ErrInvalidInput)
+	}
+	page, limit = ListPage(page, limit)
+	return fu.friendshipRepo.List(userID, kind, (page-1)*limit, limit)
+}
@@ -0,0 +1,234 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxGroupInviteUses = 100000
+
+// GroupInviteInput adalah pengaturan invite baru dari client. ExpiresAt nil dan MaxUses 0
+// berarti invite tidak kedaluwarsa dan bisa dipakai tanpa batas.
+type GroupInviteInput struct {
+	ExpiresAt        *time.Time `json:"expires_at"`
+	MaxUses          int        `json:"max_uses"`
+	RequiresApproval bool       `json:"requires_approval"`
+}
+
+type GroupInviteUseCase struct {
+	inviteRepo         repositories.GroupInviteRepository
+	groupRepo          repositories.GroupRepository
+	friendshipRepo     repositories.FriendshipRepository
+	permissionUseCase  *GroupPermissionUseCase
+	joinRequestUseCase *GroupJoinRequestUseCase
+	privacyUseCase     *PrivacyUseCase
+	publisher          EventPublisher
+}
+
+func NewGroupInviteUseCase(inviteRepo repositories.GroupInviteRepository, groupRepo repositories.GroupRepository, friendshipRepo repositories.FriendshipRepository, permissionUseCase *GroupPermissionUseCase, joinRequestUseCase *GroupJoinRequestUseCase, privacyUseCase *PrivacyUseCase, publisher EventPublisher) *GroupInviteUseCase {
+	return &GroupInviteUseCase{
+		inviteRepo:         inviteRepo,
+		groupRepo:          groupRepo,
+		friendshipRepo:     friendshipRepo,
+		permissionUseCase:  permissionUseCase,
+		joinRequestUseCase: joinRequestUseCase,
+		privacyUseCase:     privacyUseCase,
+		publisher:          publisher,
+	}
+}
+
+var errInviteUnusable = fmt.Errorf("%w: invite link is invalid or has expired", ErrNotFound)
+
+// Create membuat invite link grup (butuh permission manage_invites)
+func (iu *GroupInviteUseCase) Create(userID, groupID uint, input GroupInviteInput) (*models.GroupInvite, error) {
+	now := time.Now().UTC()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidInput)
+	}
+	if input.MaxUses < 0 || input.MaxUses > maxGroupInviteUses {
+		return nil, fmt.Errorf("%w: max_uses must be between 0 and %d", ErrInvalidInput, maxGroupInviteUses)
+	}
+
+	if _, _, err := iu.permissionUseCase.Authorize(groupID, userID, models.PermissionManageInvites); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	invite := &models.GroupInvite{
+		GroupID:          groupID,
+		CreatorID:        userID,
+		Token:            token,
+		ExpiresAt:        input.ExpiresAt,
+		MaxUses:          input.MaxUses,
+		RequiresApproval: input.RequiresApproval,
+	}
+	if err := iu.inviteRepo.Create(invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// List mengambil semua invite grup, termasuk yang sudah dicabut atau kedaluwarsa
+func (iu *GroupInviteUseCase) List(userID, groupID uint) ([]models.GroupInvite, error) {
+	if _, _, err := iu.permissionUseCase.Authorize(groupID, userID, models.PermissionManageInvites); err != nil {
+		return nil, err
+	}
+	invites, err := iu.inviteRepo.ListForGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	creators := make([]*models.User, len(invites))
+	for i := range invites {
+		creators[i] = &invites[i].Creator
+	}
+	if err := iu.privacyUseCase.filterPresenceOf(userID, creators); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// groupInvite mengambil invite milik grup setelah memastikan userID boleh mengelola invite
+func (iu *GroupInviteUseCase) groupInvite(userID, groupID, inviteID uint) (*models.GroupInvite, error) {
+	if _, _, err := iu.permissionUseCase.Authorize(groupID, userID, models.PermissionManageInvites); err != nil {
+		return nil, err
+	}
+	invite, err := iu.inviteRepo.FindByID(inviteID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && invite.GroupID != groupID) {
+		return nil, fmt.Errorf("%w: invite not found", ErrNotFound)
+	}
+	return invite, err
+}
+
+// Revoke mencabut invite sehingga tidak bisa dipakai lagi
+func (iu *GroupInviteUseCase) Revoke(userID, groupID, inviteID uint) error {
+	invite, err := iu.groupInvite(userID, groupID, inviteID)
+	if err != nil {
+		return err
+	}
+	revoked, err := iu.inviteRepo.Revoke(invite.ID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fmt.Errorf("%w: invite is already revoked", ErrConflict)
+	}
+	return nil
+}
+
+// Uses mengambil daftar user yang bergabung (atau meminta bergabung) lewat invite
+func (iu *GroupInviteUseCase) Uses(userID, groupID, inviteID uint) ([]models.GroupInviteUse, error) {
+	invite, err := iu.groupInvite(userID, groupID, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	uses, err := iu.inviteRepo.Uses(invite.ID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*models.User, len(uses))
+	for i := range uses {
+		users[i] = &uses[i].User
+	}
+	if err := iu.privacyUseCase.filterPresenceOf(userID, users); err != nil {
+		return nil, err
+	}
+	return uses, nil
+}
+
+// usableInvite mengambil invite dari token beserta grupnya, selama invite masih bisa dipakai
+func (iu *GroupInviteUseCase) usableInvite(token string) (*models.GroupInvite, *models.Group, error) {
+	invite, err := iu.inviteRepo.FindByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errInviteUnusable
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !invite.Usable(time.Now().UTC()) {
+		return nil, nil, errInviteUnusable
+	}
+
+	group, err := iu.groupRepo.FindByID(invite.GroupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errInviteUnusable
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return invite, group, nil
+}
+
+// Preview menampilkan info dasar grup dari invite, bisa dilihat semua user yang login
+func (iu *GroupInviteUseCase) Preview(userID uint, token string) (*models.GroupInvitePreview, error) {
+	invite, group, err := iu.usableInvite(token)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := previewGroup(iu.groupRepo, group, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GroupInvitePreview{
+		GroupPreview:     *preview,
+		RequiresApproval: invite.RequiresApproval,
+		ExpiresAt:        invite.ExpiresAt,
+	}, nil
+}
+
+// Join memakai invite untuk bergabung ke grup. Jika invite butuh persetujuan, yang dibuat
+// adalah join request pending berisi jawaban pertanyaan join grup, dan status yang
+// dikembalikan "requested".
+func (iu *GroupInviteUseCase) Join(userID uint, token string, answers []string) (string, *models.Group, error) {
+	invite, group, err := iu.usableInvite(token)
+	if err != nil {
+		return "", nil, err
+	}
+	// pembuat invite menambahkan user lewat link, jadi block diperiksa seperti pada AddMembers
+	blocked, err := iu.friendshipRepo.Blocked(invite.CreatorID, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if blocked {
+		return "", nil, ErrBlocked
+	}
+	request := &models.GroupJoinRequest{UserID: userID}
+	if invite.RequiresApproval {
+		if request.Answers, err = joinAnswers(group, answers); err != nil {
+			return "", nil, err
+		}
+	}
+
+	result, err := iu.inviteRepo.Redeem(invite.ID, request, time.Now().UTC())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, errInviteUnusable
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	switch result {
+	case repositories.InviteUnusable:
+		return "", nil, errInviteUnusable
+	case repositories.InviteAlreadyMember:
+		return "", nil, fmt.Errorf("%w: already a member of this group", ErrConflict)
+	case repositories.InviteAlreadyRequested:
+		return "", nil, fmt.Errorf("%w: a join request is already pending", ErrConflict)
+	case repositories.InviteRequested:
+		iu.joinRequestUseCase.requested(group, request.ID)
+		return models.GroupInviteUseRequested, group, nil
+	}
+
+	members, err := iu.groupRepo.MemberIDs(group.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	iu.publisher.PublishToUsers(members, groupEvent(models.GroupActionMembersAdded, group, []uint{userID}))
+	return models.GroupInviteUseJoined, group, nil
+}
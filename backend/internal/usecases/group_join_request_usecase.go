@@ -0,0 +1,285 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxGroupJoinAnswerLength = 1000
+	joinRequestExpireBatch   = 100
+)
+
+type GroupJoinRequestConfig struct {
+	TTL time.Duration // join request yang pending lebih lama dari ini menjadi expired
+}
+
+type GroupJoinRequestUseCase struct {
+	joinRequestRepo   repositories.GroupJoinRequestRepository
+	groupRepo         repositories.GroupRepository
+	friendshipRepo    repositories.FriendshipRepository
+	permissionUseCase *GroupPermissionUseCase
+	privacyUseCase    *PrivacyUseCase
+	publisher         EventPublisher
+	config            GroupJoinRequestConfig
+}
+
+func NewGroupJoinRequestUseCase(joinRequestRepo repositories.GroupJoinRequestRepository, groupRepo repositories.GroupRepository, friendshipRepo repositories.FriendshipRepository, permissionUseCase *GroupPermissionUseCase, privacyUseCase *PrivacyUseCase, publisher EventPublisher, config GroupJoinRequestConfig) *GroupJoinRequestUseCase {
+	return &GroupJoinRequestUseCase{
+		joinRequestRepo:   joinRequestRepo,
+		groupRepo:         groupRepo,
+		friendshipRepo:    friendshipRepo,
+		permissionUseCase: permissionUseCase,
+		privacyUseCase:    privacyUseCase,
+		publisher:         publisher,
+		config:            config,
+	}
+}
+
+// joinAnswers memasangkan jawaban requester dengan pertanyaan join grup. Setiap pertanyaan
+// wajib dijawab; jawaban diabaikan jika grup tidak punya pertanyaan.
+func joinAnswers(group *models.Group, answers []string) ([]models.GroupJoinAnswer, error) {
+	if len(group.JoinQuestions) == 0 {
+		return nil, nil
+	}
+	if len(answers) != len(group.JoinQuestions) {
+		return nil, fmt.Errorf("%w: answer each of the %d join questions", ErrInvalidInput, len(group.JoinQuestions))
+	}
+
+	result := make([]models.GroupJoinAnswer, len(answers))
+	for i, answer := range answers {
+		answer = strings.TrimSpace(answer)
+		if answer == "" || utf8.RuneCountInString(answer) > maxGroupJoinAnswerLength {
+			return nil, fmt.Errorf("%w: each answer must be between 1 and %d characters", ErrInvalidInput, maxGroupJoinAnswerLength)
+		}
+		result[i] = models.GroupJoinAnswer{Question: group.JoinQuestions[i], Answer: answer}
+	}
+	return result, nil
+}
+
+// publish mengirim event join request ke anggota yang bisa menyetujuinya, dan ke requester
+// untuk semua action selain requested. Presence requester tidak ikut dikirim.
+func (ju *GroupJoinRequestUseCase) publish(group *models.Group, request *models.GroupJoinRequest, action string) {
+	userIDs, err := ju.permissionUseCase.MembersWith(group, models.PermissionAddMembers)
+	if err != nil {
+		log.Printf("Failed to load approvers of group %d: %v", group.ID, err)
+	}
+	if action != models.GroupJoinRequestActionRequested && !containsID(userIDs, request.UserID) {
+		userIDs = append(userIDs, request.UserID)
+	}
+	payload := models.GroupJoinRequestEvent{Action: action, Request: *request}
+	payload.Request.User.HidePresence()
+	ju.publisher.PublishToUsers(userIDs, models.NewEvent(models.EventJoinRequest, payload))
+}
+
+// requested memberi tahu approver bahwa ada join request baru
+func (ju *GroupJoinRequestUseCase) requested(group *models.Group, requestID uint) {
+	request, err := ju.joinRequestRepo.FindByID(requestID)
+	if err != nil {
+		log.Printf("Failed to load join request %d: %v", requestID, err)
+		return
+	}
+	ju.publish(group, request, models.GroupJoinRequestActionRequested)
+}
+
+// Join bergabung ke grup terbuka (IsOpen). Grup publik langsung menambahkan user sebagai
+// member, sedangkan grup private membuat join request pending yang harus disetujui admin
+// atau moderator. Grup tertutup hanya bisa dimasuki lewat invite link.
+// Status yang dikembalikan "joined" atau "requested".
+func (ju *GroupJoinRequestUseCase) Join(userID, groupID uint, answers []string) (string, *models.Group, error) {
+	group, err := ju.groupRepo.FindByID(groupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !group.IsOpen) {
+		return "", nil, ErrNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !group.IsPrivate {
+		added, err := ju.groupRepo.AddMembers(groupID, []uint{userID})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, ErrNotFound
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if len(added) == 0 {
+			return "", nil, fmt.Errorf("%w: already a member of this group", ErrConflict)
+		}
+
+		members, err := ju.groupRepo.MemberIDs(groupID)
+		if err != nil {
+			return "", nil, err
+		}
+		ju.publisher.PublishToUsers(members, groupEvent(models.GroupActionMembersAdded, group, added))
+		return models.GroupInviteUseJoined, group, nil
+	}
+
+	request := &models.GroupJoinRequest{GroupID: groupID, UserID: userID}
+	if request.Answers, err = joinAnswers(group, answers); err != nil {
+		return "", nil, err
+	}
+	result, err := ju.joinRequestRepo.Create(request)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, ErrNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	switch result {
+	case repositories.JoinRequestAlreadyMember:
+		return "", nil, fmt.Errorf("%w: already a member of this group", ErrConflict)
+	case repositories.JoinRequestAlreadyPending:
+		return "", nil, fmt.Errorf("%w: a join request is already pending", ErrConflict)
+	}
+
+	ju.requested(group, request.ID)
+	return models.GroupInviteUseRequested, group, nil
+}
+
+// List mengambil antrian join request pending di grup (butuh permission add_members)
+func (ju *GroupJoinRequestUseCase) List(userID, groupID uint, page, limit int) ([]models.GroupJoinRequest, int64, error) {
+	page, limit = ListPage(page, limit)
+	if _, _, err := ju.permissionUseCase.Authorize(groupID, userID, models.PermissionAddMembers); err != nil {
+		return nil, 0, err
+	}
+	requests, total, err := ju.joinRequestRepo.Pending(groupID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	users := make([]*models.User, len(requests))
+	for i := range requests {
+		users[i] = &requests[i].User
+	}
+	if err := ju.privacyUseCase.filterPresenceOf(userID, users); err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// Approve menyetujui join request sehingga user menjadi member grup
+func (ju *GroupJoinRequestUseCase) Approve(userID, groupID, requestID uint) (*models.GroupJoinRequest, error) {
+	return ju.review(userID, groupID, requestID, models.GroupJoinRequestApproved)
+}
+
+// Reject menolak join request
+func (ju *GroupJoinRequestUseCase) Reject(userID, groupID, requestID uint) (*models.GroupJoinRequest, error) {
+	return ju.review(userID, groupID, requestID, models.GroupJoinRequestRejected)
+}
+
+func (ju *GroupJoinRequestUseCase) review(userID, groupID, requestID uint, status string) (*models.GroupJoinRequest, error) {
+	group, _, err := ju.permissionUseCase.Authorize(groupID, userID, models.PermissionAddMembers)
+	if err != nil {
+		return nil, err
+	}
+	request, err := ju.joinRequestRepo.FindByID(requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && request.GroupID != groupID) {
+		return nil, fmt.Errorf("%w: join request not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == models.GroupJoinRequestApproved {
+		// menyetujui sama dengan menambahkan member, block diperiksa seperti pada AddMembers
+		blocked, err := ju.friendshipRepo.Blocked(userID, request.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrBlocked
+		}
+	}
+
+	result, err := ju.joinRequestRepo.Review(request.ID, userID, status, time.Now().UTC())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch result {
+	case repositories.JoinRequestNotPending:
+		return nil, fmt.Errorf("%w: join request is no longer pending", ErrConflict)
+	case repositories.JoinRequestInviteUnusable:
+		return nil, fmt.Errorf("%w: the invite link of this request is revoked, expired or used up", ErrConflict)
+	}
+
+	if request, err = ju.joinRequestRepo.FindByID(requestID); err != nil {
+		return nil, err
+	}
+	action := models.GroupJoinRequestActionRejected
+	if status == models.GroupJoinRequestApproved {
+		action = models.GroupJoinRequestActionApproved
+		members, err := ju.groupRepo.MemberIDs(groupID)
+		if err != nil {
+			return nil, err
+		}
+		ju.publisher.PublishToUsers(members, groupEvent(models.GroupActionMembersAdded, group, []uint{request.UserID}))
+	}
+	ju.publish(group, request, action)
+	return request, nil
+}
+
+// Expire menandai join request yang pending lebih lama dari TTL sebagai expired, lalu
+// memberi tahu requester dan approver grupnya
+func (ju *GroupJoinRequestUseCase) Expire() (int, error) {
+	now := time.Now().UTC()
+	groups := map[uint]*models.Group{}
+	expired := 0
+
+	for {
+		requests, err := ju.joinRequestRepo.Expire(now.Add(-ju.config.TTL), now, joinRequestExpireBatch)
+		if err != nil {
+			return expired, err
+		}
+		expired += len(requests)
+
+		for i := range requests {
+			groupID := requests[i].GroupID
+			if _, ok := groups[groupID]; !ok {
+				group, err := ju.groupRepo.FindByID(groupID)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Printf("Failed to load group %d: %v", groupID, err)
+				}
+				if err != nil {
+					group = nil
+				}
+				groups[groupID] = group
+			}
+			// grup yang sudah dihapus tidak punya approver untuk diberi tahu
+			if groups[groupID] == nil {
+				continue
+			}
+			ju.publish(groups[groupID], &requests[i], models.GroupJoinRequestActionExpired)
+		}
+		if len(requests) < joinRequestExpireBatch {
+			return expired, nil
+		}
+	}
+}
+
+// StartExpiry menjalankan Expire secara berkala
+func (ju *GroupJoinRequestUseCase) StartExpiry(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			expired, err := ju.Expire()
+			if err != nil {
+				log.Printf("Join request expiry failed: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("Expired %d join requests", expired)
+			}
+		}
+	}()
+}
@@ -0,0 +1,195 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// GroupPermissionUseCase adalah satu-satunya tempat aturan akses grup diputuskan. Setiap
+// usecase yang bekerja dalam lingkup grup memeriksa akses lewat Authorize.
+type GroupPermissionUseCase struct {
+	groupRepo      repositories.GroupRepository
+	permissionRepo repositories.GroupPermissionRepository
+	publisher      EventPublisher
+}
+
+func NewGroupPermissionUseCase(groupRepo repositories.GroupRepository, permissionRepo repositories.GroupPermissionRepository, publisher EventPublisher) *GroupPermissionUseCase {
+	return &GroupPermissionUseCase{
+		groupRepo:      groupRepo,
+		permissionRepo: permissionRepo,
+		publisher:      publisher,
+	}
+}
+
+// Authorize memastikan userID adalah anggota grup dan memiliki permission (kosong berarti
+// cukup menjadi anggota), lalu mengembalikan grup beserta keanggotaan userID
+func (pu *GroupPermissionUseCase) Authorize(groupID, userID uint, permission string) (*models.Group, *models.GroupMember, error) {
+	group, err := pu.groupRepo.FindByID(groupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	member, err := pu.groupRepo.FindMember(groupID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if permission != "" {
+		if err := pu.Require(group, member, permission); err != nil {
+			return nil, nil, err
+		}
+	}
+	return group, member, nil
+}
+
+// Require memastikan anggota yang sudah dimuat memiliki permission
+func (pu *GroupPermissionUseCase) Require(group *models.Group, member *models.GroupMember, permission string) error {
+	permissions, err := pu.permissions(group, member)
+	if err != nil {
+		return err
+	}
+	if !permissions[permission] {
+		return fmt.Errorf("%w: missing %s permission", ErrForbidden, permission)
+	}
+	return nil
+}
+
+// memberRole mengembalikan role yang dipakai untuk menghitung permission anggota, pemilik
+// grup selalu dianggap admin
+func memberRole(group *models.Group, member *models.GroupMember) string {
+	if group.OwnerID == member.UserID {
+		return models.GroupRoleAdmin
+	}
+	return member.Role
+}
+
+// permissions menghitung permission efektif anggota. Pemilik grup dan admin selalu memiliki
+// semua permission, sehingga override tidak bisa mengunci grup.
+func (pu *GroupPermissionUseCase) permissions(group *models.Group, member *models.GroupMember) (map[string]bool, error) {
+	role := memberRole(group, member)
+	if role == models.GroupRoleAdmin {
+		return models.GroupRolePermissions(role, nil), nil
+	}
+
+	overrides, err := pu.permissionRepo.Find(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	return models.GroupRolePermissions(role, overrides), nil
+}
+
+// MembersWith mengambil ID anggota grup yang memiliki permission
+func (pu *GroupPermissionUseCase) MembersWith(group *models.Group, permission string) ([]uint, error) {
+	members, err := pu.groupRepo.Members(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := pu.permissionRepo.Find(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]map[string]bool, len(models.GroupRoles))
+	for _, role := range models.GroupRoles {
+		roles[role] = models.GroupRolePermissions(role, overrides)
+	}
+	ids := []uint{}
+	for i := range members {
+		if roles[memberRole(group, &members[i])][permission] {
+			ids = append(ids, members[i].UserID)
+		}
+	}
+	return ids, nil
+}
+
+// Effective mengambil role dan permission efektif userID di grup
+func (pu *GroupPermissionUseCase) Effective(userID, groupID uint) (string, map[string]bool, error) {
+	group, member, err := pu.Authorize(groupID, userID, "")
+	if err != nil {
+		return "", nil, err
+	}
+	permissions, err := pu.permissions(group, member)
+	if err != nil {
+		return "", nil, err
+	}
+	return member.Role, permissions, nil
+}
+
+// Roles mengambil matriks permission setiap role di grup
+func (pu *GroupPermissionUseCase) Roles(userID, groupID uint) (map[string]map[string]bool, error) {
+	if _, _, err := pu.Authorize(groupID, userID, ""); err != nil {
+		return nil, err
+	}
+	overrides, err := pu.permissionRepo.Find(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]map[string]bool, len(models.GroupRoles))
+	for _, role := range models.GroupRoles {
+		roles[role] = models.GroupRolePermissions(role, overrides)
+	}
+	return roles, nil
+}
+
+// UpdateRole mengubah permission role di grup. Hanya perubahan yang berbeda dari matriks
+// default yang disimpan sebagai override. Permission admin tidak bisa diubah.
+func (pu *GroupPermissionUseCase) UpdateRole(userID, groupID uint, role string, changes map[string]bool) (map[string]bool, error) {
+	if role != models.GroupRoleModerator && role != models.GroupRoleMember {
+		return nil, fmt.Errorf("%w: role must be moderator or member", ErrInvalidInput)
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: permissions are required", ErrInvalidInput)
+	}
+	for permission := range changes {
+		if !slices.Contains(models.GroupPermissions, permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q", ErrInvalidInput, permission)
+		}
+	}
+
+	group, _, err := pu.Authorize(groupID, userID, models.PermissionChangeSettings)
+	if err != nil {
+		return nil, err
+	}
+	current, err := pu.permissionRepo.Find(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := models.GroupRolePermissions(role, current)
+	for permission, allowed := range changes {
+		permissions[permission] = allowed
+	}
+	overrides := []models.GroupPermissionOverride{}
+	for _, permission := range models.GroupPermissions {
+		if permissions[permission] != models.DefaultGroupPermission(role, permission) {
+			overrides = append(overrides, models.GroupPermissionOverride{
+				GroupID:    groupID,
+				Role:       role,
+				Permission: permission,
+				Allowed:    permissions[permission],
+			})
+		}
+	}
+	if err := pu.permissionRepo.Replace(groupID, role, overrides); err != nil {
+		return nil, err
+	}
+
+	members, err := pu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	pu.publisher.PublishToUsers(members, groupEvent(models.GroupActionPermissionsUpdated, group, nil))
+	return permissions, nil
+}
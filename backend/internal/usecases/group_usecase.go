@@ -0,0 +1,581 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxGroupNameLength        = 100
+	maxGroupDescriptionLength = 1000
+	maxGroupAvatarURLLength   = 255
+	maxGroupAddBatch          = 100
+
+	maxGroupJoinQuestions      = 5
+	maxGroupJoinQuestionLength = 200
+)
+
+// GroupInput adalah info grup dari client, field nil tidak diubah.
+// Name wajib diisi saat membuat grup.
+type GroupInput struct {
+	Name          *string   `json:"name"`
+	Description   *string   `json:"description"`
+	AvatarURL     *string   `json:"avatar_url"`
+	IsPrivate     *bool     `json:"is_private"`
+	IsOpen        *bool     `json:"is_open"`
+	JoinQuestions *[]string `json:"join_questions"`
+}
+
+type GroupUseCase struct {
+	groupRepo         repositories.GroupRepository
+	userRepo          repositories.UserRepository
+	friendshipRepo    repositories.FriendshipRepository
+	privacyUseCase    *PrivacyUseCase
+	permissionUseCase *GroupPermissionUseCase
+	publisher         EventPublisher
+
+	onMemberLeft []func(groupID, userID uint) // anggota keluar atau dikeluarkan dari grup
+	onDeleted    []func(groupID uint)         // grup dihapus
+}
+
+func NewGroupUseCase(groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyUseCase *PrivacyUseCase, permissionUseCase *GroupPermissionUseCase, publisher EventPublisher) *GroupUseCase {
+	return &GroupUseCase{
+		groupRepo:         groupRepo,
+		userRepo:          userRepo,
+		friendshipRepo:    friendshipRepo,
+		privacyUseCase:    privacyUseCase,
+		permissionUseCase: permissionUseCase,
+		publisher:         publisher,
+	}
+}
+
+// OnMember mendaftarkan callback saat anggota keluar/dikeluarkan dari grup dan saat grup
+// dihapus. Callback yang nil dilewati.
+func (gu *GroupUseCase) OnMember(onLeft func(groupID, userID uint), onDeleted func(groupID uint)) {
+	if onLeft != nil {
+		gu.onMemberLeft = append(gu.onMemberLeft, onLeft)
+	}
+	if onDeleted != nil {
+		gu.onDeleted = append(gu.onDeleted, onDeleted)
+	}
+}
+
+func (gu *GroupUseCase) memberLeft(groupID, userID uint) {
+	for _, callback := range gu.onMemberLeft {
+		callback(groupID, userID)
+	}
+}
+
+func (gu *GroupUseCase) deleted(groupID uint) {
+	for _, callback := range gu.onDeleted {
+		callback(groupID)
+	}
+}
+
+// applyGroupInput memvalidasi input lalu menerapkannya ke group
+func applyGroupInput(group *models.Group, input GroupInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxGroupNameLength {
+			return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidInput, maxGroupNameLength)
+		}
+		group.Name = name
+	}
+	if input.Description != nil {
+		if utf8.RuneCountInString(*input.Description) > maxGroupDescriptionLength {
+			return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidInput, maxGroupDescriptionLength)
+		}
+		group.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.AvatarURL != nil {
+		if len(*input.AvatarURL) > maxGroupAvatarURLLength {
+			return fmt.Errorf("%w: avatar_url must be at most %d characters", ErrInvalidInput, maxGroupAvatarURLLength)
+		}
+		group.AvatarURL = *input.AvatarURL
+	}
+	if input.IsPrivate != nil {
+		group.IsPrivate = *input.IsPrivate
+	}
+	if input.IsOpen != nil {
+		group.IsOpen = *input.IsOpen
+	}
+	if input.JoinQuestions != nil {
+		if len(*input.JoinQuestions) > maxGroupJoinQuestions {
+			return fmt.Errorf("%w: at most %d join_questions", ErrInvalidInput, maxGroupJoinQuestions)
+		}
+		questions := make([]string, len(*input.JoinQuestions))
+		for i, question := range *input.JoinQuestions {
+			questions[i] = strings.TrimSpace(question)
+			if questions[i] == "" || utf8.RuneCountInString(questions[i]) > maxGroupJoinQuestionLength {
+				return fmt.Errorf("%w: each join question must be between 1 and %d characters", ErrInvalidInput, maxGroupJoinQuestionLength)
+			}
+		}
+		group.JoinQuestions = questions
+	}
+	return nil
+}
+
+// previewGroup menyusun info grup yang boleh dilihat user sebelum bergabung
+func previewGroup(groupRepo repositories.GroupRepository, group *models.Group, userID uint) (*models.GroupPreview, error) {
+	counts, err := groupRepo.MemberCounts([]uint{group.ID})
+	if err != nil {
+		return nil, err
+	}
+	_, err = groupRepo.FindMember(group.ID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return &models.GroupPreview{
+		GroupID:       group.ID,
+		Name:          group.Name,
+		AvatarURL:     group.AvatarURL,
+		MemberCount:   counts[group.ID],
+		IsPrivate:     group.IsPrivate,
+		IsOpen:        group.IsOpen,
+		JoinQuestions: group.JoinQuestions,
+		IsMember:      err == nil,
+	}, nil
+}
+
+// groupEvent membuat event grup. Event yang sama dikirim ke semua anggota, jadi presence
+// pemilik grup disembunyikan alih-alih disaring per penerima.
+func groupEvent(action string, group *models.Group, changed []uint) models.Event {
+	payload := models.GroupEvent{Action: action, Group: *group, UserIDs: changed}
+	payload.Group.Owner.HidePresence()
+	return models.NewEvent(models.EventGroup, payload)
+}
+
+// publish mengirim event grup ke userIDs
+func (gu *GroupUseCase) publish(userIDs []uint, action string, group *models.Group, changed []uint) {
+	gu.publisher.PublishToUsers(userIDs, groupEvent(action, group, changed))
+}
+
+// Create membuat grup baru dengan userID sebagai pemilik sekaligus admin
+func (gu *GroupUseCase) Create(userID uint, input GroupInput) (*models.Group, error) {
+	if input.Name == nil {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	group := &models.Group{OwnerID: userID}
+	if err := applyGroupInput(group, input); err != nil {
+		return nil, err
+	}
+
+	if err := gu.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+	group, err := gu.groupRepo.FindByID(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	gu.publish([]uint{userID}, models.GroupActionCreated, group, nil)
+	return group, nil
+}
+
+// Update mengubah info grup (butuh permission edit_info) serta pengaturan privasi, akses
+// join dan pertanyaan join-nya (butuh permission change_settings)
+func (gu *GroupUseCase) Update(userID, groupID uint, input GroupInput) (*models.Group, error) {
+	group, member, err := gu.permissionUseCase.Authorize(groupID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil || input.Description != nil || input.AvatarURL != nil {
+		if err := gu.permissionUseCase.Require(group, member, models.PermissionEditInfo); err != nil {
+			return nil, err
+		}
+	}
+	if input.IsPrivate != nil || input.IsOpen != nil || input.JoinQuestions != nil {
+		if err := gu.permissionUseCase.Require(group, member, models.PermissionChangeSettings); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyGroupInput(group, input); err != nil {
+		return nil, err
+	}
+
+	if err := gu.groupRepo.Update(group); err != nil {
+		return nil, err
+	}
+	members, err := gu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	gu.publish(members, models.GroupActionUpdated, group, nil)
+	return group, nil
+}
+
+// Delete menghapus grup beserta keanggotaannya, hanya untuk pemilik grup
+func (gu *GroupUseCase) Delete(userID, groupID uint) error {
+	group, _, err := gu.permissionUseCase.Authorize(groupID, userID, "")
+	if err != nil {
+		return err
+	}
+	if group.OwnerID != userID {
+		return fmt.Errorf("%w: only the group owner can delete the group", ErrForbidden)
+	}
+
+	members, err := gu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return err
+	}
+	if err := gu.groupRepo.Delete(groupID); err != nil {
+		return err
+	}
+	gu.deleted(groupID)
+	gu.publish(members, models.GroupActionDeleted, group, nil)
+	return nil
+}
+
+// AddMembers menambahkan user ke grup (butuh permission add_members). User yang memblokir
+// atau diblokir penambah, atau yang tidak mengizinkan penambah menambahkannya ke grup,
+// membatalkan seluruh permintaan. User yang sudah menjadi anggota dilewati.
+func (gu *GroupUseCase) AddMembers(userID, groupID uint, userIDs []uint) ([]uint, error) {
+	if len(userIDs) == 0 || len(userIDs) > maxGroupAddBatch {
+		return nil, fmt.Errorf("%w: send between 1 and %d user_ids", ErrInvalidInput, maxGroupAddBatch)
+	}
+
+	group, _, err := gu.permissionUseCase.Authorize(groupID, userID, models.PermissionAddMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := gu.userRepo.FindByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]uint, len(users))
+	for i, user := range users {
+		found[i] = user.ID
+	}
+	for _, targetID := range userIDs {
+		if !containsID(found, targetID) {
+			return nil, fmt.Errorf("%w: user %d", ErrNotFound, targetID)
+		}
+		if targetID == userID {
+			continue
+		}
+		blocked, err := gu.friendshipRepo.Blocked(userID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, fmt.Errorf("user %d: %w", targetID, ErrBlocked)
+		}
+		if err := gu.privacyUseCase.CanAddToGroup(userID, targetID); err != nil {
+			return nil, fmt.Errorf("user %d: %w", targetID, err)
+		}
+	}
+
+	added, err := gu.groupRepo.AddMembers(groupID, userIDs)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(added) > 0 {
+		members, err := gu.groupRepo.MemberIDs(groupID)
+		if err != nil {
+			return nil, err
+		}
+		gu.publish(members, models.GroupActionMembersAdded, group, added)
+	}
+	return added, nil
+}
+
+// RemoveMember mengeluarkan anggota dari grup (butuh permission remove_members). Hanya
+// anggota dengan role di bawah role pengeluar yang bisa dikeluarkan, kecuali oleh pemilik
+// grup. Pemilik grup tidak bisa dikeluarkan.
+func (gu *GroupUseCase) RemoveMember(userID, groupID, memberID uint) error {
+	group, member, err := gu.permissionUseCase.Authorize(groupID, userID, models.PermissionRemoveMembers)
+	if err != nil {
+		return err
+	}
+	if memberID == userID {
+		return fmt.Errorf("%w: cannot remove yourself, leave the group instead", ErrInvalidInput)
+	}
+	if memberID == group.OwnerID {
+		return fmt.Errorf("%w: the group owner cannot be removed", ErrForbidden)
+	}
+
+	target, err := gu.groupRepo.FindMember(groupID, memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if userID != group.OwnerID && models.GroupRoleRank(target.Role) >= models.GroupRoleRank(member.Role) {
+		return fmt.Errorf("%w: cannot remove a member with the same or a higher role", ErrForbidden)
+	}
+
+	members, err := gu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return err
+	}
+	removed, err := gu.groupRepo.RemoveMember(groupID, memberID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	gu.memberLeft(groupID, memberID)
+	gu.publish(members, models.GroupActionMemberRemoved, group, []uint{memberID})
+	return nil
+}
+
+// UpdateMemberRole mengubah role anggota grup (butuh permission change_settings). Selain
+// pemilik grup, user hanya bisa mengubah anggota dengan role di bawahnya dan tidak bisa
+// memberi role yang lebih tinggi dari role-nya sendiri.
+func (gu *GroupUseCase) UpdateMemberRole(userID, groupID, memberID uint, role string) (*models.GroupMember, error) {
+	if !slices.Contains(models.GroupRoles, role) {
+		return nil, fmt.Errorf("%w: role must be admin, moderator or member", ErrInvalidInput)
+	}
+	group, member, err := gu.permissionUseCase.Authorize(groupID, userID, models.PermissionChangeSettings)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == userID {
+		return nil, fmt.Errorf("%w: cannot change your own role", ErrInvalidInput)
+	}
+	if memberID == group.OwnerID {
+		return nil, fmt.Errorf("%w: the group owner is always an admin", ErrForbidden)
+	}
+
+	target, err := gu.groupRepo.FindMember(groupID, memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userID != group.OwnerID {
+		rank := models.GroupRoleRank(memberRole(group, member))
+		if models.GroupRoleRank(target.Role) >= rank {
+			return nil, fmt.Errorf("%w: cannot change the role of a member with the same or a higher role", ErrForbidden)
+		}
+		if models.GroupRoleRank(role) > rank {
+			return nil, fmt.Errorf("%w: cannot assign a role higher than your own", ErrForbidden)
+		}
+	}
+	if target.Role == role {
+		return target, nil
+	}
+
+	updated, err := gu.groupRepo.UpdateMemberRole(groupID, memberID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: member has left or became the owner", ErrConflict)
+	}
+	target.Role = role
+
+	members, err := gu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	gu.publish(members, models.GroupActionRoleChanged, group, []uint{memberID})
+	return target, nil
+}
+
+// Leave mengeluarkan userID dari grup. Jika userID pemilik grup, kepemilikan otomatis pindah
+// ke admin yang paling lama bergabung; jika userID anggota terakhir, grup ikut dihapus.
+func (gu *GroupUseCase) Leave(userID, groupID uint) error {
+	group, _, err := gu.permissionUseCase.Authorize(groupID, userID, "")
+	if err != nil {
+		return err
+	}
+	return gu.leave(userID, group)
+}
+
+func (gu *GroupUseCase) leave(userID uint, group *models.Group) error {
+	members, err := gu.groupRepo.MemberIDs(group.ID)
+	if err != nil {
+		return err
+	}
+	result, err := gu.groupRepo.Leave(group.ID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotMember
+	}
+	if err != nil {
+		return err
+	}
+	return gu.left(userID, group, members, result)
+}
+
+// left memberi tahu members (anggota sebelum userID keluar) bahwa userID keluar dari grup,
+// grup terhapus, atau pemilik grup berganti
+func (gu *GroupUseCase) left(userID uint, group *models.Group, members []uint, result *repositories.GroupLeaveResult) error {
+	if result.Deleted {
+		gu.deleted(group.ID)
+		gu.publish(members, models.GroupActionDeleted, group, nil)
+		return nil
+	}
+	gu.memberLeft(group.ID, userID)
+	gu.publish(members, models.GroupActionMemberLeft, group, []uint{userID})
+	if result.NewOwnerID != 0 {
+		_, err := gu.ownerChanged(group.ID, result.NewOwnerID)
+		return err
+	}
+	return nil
+}
+
+// ownerChanged memberi tahu semua anggota bahwa grup punya pemilik baru, lalu mengembalikan
+// grup terbaru
+func (gu *GroupUseCase) ownerChanged(groupID, ownerID uint) (*models.Group, error) {
+	group, err := gu.groupRepo.FindByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := gu.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	gu.publish(members, models.GroupActionOwnerChanged, group, []uint{ownerID})
+	return group, nil
+}
+
+// memberGroup adalah grup yang diikuti user beserta anggotanya saat itu
+type memberGroup struct {
+	group   *models.Group
+	members []uint
+}
+
+// memberGroups mengambil semua grup yang diikuti userID beserta anggotanya, dipakai untuk
+// memberi tahu anggota grup setelah akun userID dihapus
+func (gu *GroupUseCase) memberGroups(userID uint) (map[uint]memberGroup, error) {
+	groupIDs, err := gu.groupRepo.GroupIDsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[uint]memberGroup, len(groupIDs))
+	for _, groupID := range groupIDs {
+		group, err := gu.groupRepo.FindByID(groupID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		members, err := gu.groupRepo.MemberIDs(groupID)
+		if err != nil {
+			return nil, err
+		}
+		groups[groupID] = memberGroup{group: group, members: members}
+	}
+	return groups, nil
+}
+
+// TransferOwnership memindahkan kepemilikan grup ke anggota lain, hanya untuk pemilik grup.
+// Pemilik baru menjadi admin, pemilik lama tetap menjadi admin.
+func (gu *GroupUseCase) TransferOwnership(userID, groupID, newOwnerID uint) (*models.Group, error) {
+	group, _, err := gu.permissionUseCase.Authorize(groupID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if group.OwnerID != userID {
+		return nil, fmt.Errorf("%w: only the group owner can transfer ownership", ErrForbidden)
+	}
+	if newOwnerID == userID {
+		return nil, fmt.Errorf("%w: you already own this group", ErrInvalidInput)
+	}
+
+	transferred, err := gu.groupRepo.TransferOwnership(groupID, userID, newOwnerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: user %d is not a member of this group", ErrNotFound, newOwnerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !transferred {
+		return nil, fmt.Errorf("%w: ownership has already changed", ErrConflict)
+	}
+
+	return gu.ownerChanged(groupID, newOwnerID)
+}
+
+// List mengambil grup yang diikuti user beserta role user dan jumlah anggotanya
+func (gu *GroupUseCase) List(userID uint, page, limit int) ([]models.GroupSummary, int64, error) {
+	page, limit = ListPage(page, limit)
+	memberships, total, err := gu.groupRepo.ListForUser(userID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	groupIDs := make([]uint, len(memberships))
+	for i, membership := range memberships {
+		groupIDs[i] = membership.GroupID
+	}
+	counts, err := gu.groupRepo.MemberCounts(groupIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups := make([]models.GroupSummary, len(memberships))
+	owners := make([]*models.User, len(memberships))
+	for i, membership := range memberships {
+		groups[i] = models.GroupSummary{
+			Group:       membership.Group,
+			Role:        membership.Role,
+			MemberCount: counts[membership.GroupID],
+		}
+		owners[i] = &groups[i].Group.Owner
+	}
+	if err := gu.privacyUseCase.filterPresenceOf(userID, owners); err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+// Preview mengambil info dasar grup agar user bisa memutuskan untuk bergabung. Grup
+// tertutup hanya bisa dilihat anggotanya; user lain melihatnya lewat invite link.
+func (gu *GroupUseCase) Preview(userID, groupID uint) (*models.GroupPreview, error) {
+	group, err := gu.groupRepo.FindByID(groupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	preview, err := previewGroup(gu.groupRepo, group, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsOpen && !preview.IsMember {
+		return nil, ErrNotFound
+	}
+	return preview, nil
+}
+
+// Get mengambil detail grup beserta anggota dan role-nya, hanya untuk anggota
+func (gu *GroupUseCase) Get(userID, groupID uint) (*models.GroupDetail, error) {
+	group, member, err := gu.permissionUseCase.Authorize(groupID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := gu.groupRepo.Members(groupID)
+	if err != nil {
+		return nil, err
+	}
+	detail := &models.GroupDetail{Group: *group, Role: member.Role, Members: make([]models.GroupMemberInfo, len(members))}
+	users := []*models.User{&detail.Group.Owner}
+	for i, m := range members {
+		detail.Members[i] = models.GroupMemberInfo{User: m.User, Role: m.Role, JoinedAt: m.JoinedAt}
+		users = append(users, &detail.Members[i].User)
+	}
+	if err := gu.privacyUseCase.filterPresenceOf(userID, users); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
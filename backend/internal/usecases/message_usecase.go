@@ -0,0 +1,584 @@
+package usecases
+
+import (
+	"context"
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSearchLimit = 50
+
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+var messageTypes = map[string]bool{"text": true, "image": true, "file": true, "audio": true, "video": true}
+
+// searchMessageTypes adalah tipe yang bisa dipakai sebagai filter pencarian: tipe yang dikirim
+// user ditambah pesan "call" dan "system" yang ditulis server
+var searchMessageTypes = map[string]bool{"text": true, "image": true, "file": true, "audio": true, "video": true, "call": true, "system": true}
+
+// SendMessageInput adalah data pesan baru dari client.
+// Tepat satu dari RecipientID (DM) atau GroupID (grup) harus diisi.
+type SendMessageInput struct {
+	RecipientID *uint               `json:"recipient_id"`
+	GroupID     *uint               `json:"group_id"`
+	Content     string              `json:"content"`
+	Type        string              `json:"type"`
+	Attachments []models.Attachment `json:"attachments"`
+	ReplyToID   *primitive.ObjectID `json:"reply_to_id"`
+}
+
+type MessageUseCase struct {
+	messageRepo       repositories.MessageRepository
+	conversationRepo  repositories.ConversationRepository
+	groupRepo         repositories.GroupRepository
+	userRepo          repositories.UserRepository
+	friendshipRepo    repositories.FriendshipRepository
+	privacyUseCase    *PrivacyUseCase
+	permissionUseCase *GroupPermissionUseCase
+	publisher         EventPublisher
+}
+
+func NewMessageUseCase(messageRepo repositories.MessageRepository, conversationRepo repositories.ConversationRepository, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyUseCase *PrivacyUseCase, permissionUseCase *GroupPermissionUseCase, publisher EventPublisher) *MessageUseCase {
+	return &MessageUseCase{
+		messageRepo:       messageRepo,
+		conversationRepo:  conversationRepo,
+		groupRepo:         groupRepo,
+		userRepo:          userRepo,
+		friendshipRepo:    friendshipRepo,
+		privacyUseCase:    privacyUseCase,
+		permissionUseCase: permissionUseCase,
+		publisher:         publisher,
+	}
+}
+
+// Send memvalidasi pesan dari user lalu mengirimkannya ke DM atau grup tujuan
+func (mu *MessageUseCase) Send(userID uint, input SendMessageInput) (*models.ChatMessage, *models.Conversation, error) {
+	if (input.RecipientID == nil) == (input.GroupID == nil) {
+		return nil, nil, fmt.Errorf("%w: exactly one of recipient_id or group_id is required", ErrInvalidInput)
+	}
+	input.Content = strings.TrimSpace(input.Content)
+	if input.Content == "" && len(input.Attachments) == 0 {
+		return nil, nil, fmt.Errorf("%w: message content is empty", ErrInvalidInput)
+	}
+	if input.Type == "" {
+		input.Type = "text"
+	}
+	if !messageTypes[input.Type] {
+		return nil, nil, fmt.Errorf("%w: unsupported message type %q", ErrInvalidInput, input.Type)
+	}
+
+	conversation, members, err := mu.resolveConversation(userID, input.RecipientID, input.GroupID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	message := &models.ChatMessage{
+		Content:     input.Content,
+		Type:        input.Type,
+		SenderID:    userID,
+		RecipientID: input.RecipientID,
+		GroupID:     input.GroupID,
+		Attachments: input.Attachments,
+		ReplyToID:   input.ReplyToID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := mu.deliver(conversation, members, message); err != nil {
+		return nil, nil, err
+	}
+	return message, conversation, nil
+}
+
+// RecordCall menulis pesan "call" berisi ringkasan call ke DM kedua user atas nama caller
+func (mu *MessageUseCase) RecordCall(call *models.Call) (*models.ChatMessage, error) {
+	conversation, err := mu.conversationRepo.FindOrCreateDM(call.CallerID, call.CalleeID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.CallSummary{
+		CallID:   call.ID,
+		Media:    call.Media,
+		Status:   call.Status,
+		Duration: int64(call.Duration().Seconds()),
+		Missed:   call.Status == models.CallStatusMissed,
+	}
+	now := time.Now().UTC()
+	recipientID := call.CalleeID
+	message := &models.ChatMessage{
+		Content:     callContent(summary),
+		Type:        "call",
+		SenderID:    call.CallerID,
+		RecipientID: &recipientID,
+		Call:        &summary,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := mu.deliver(conversation, []uint{call.CallerID, call.CalleeID}, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// RecordRoom menulis pesan system ke grup saat room call dimulai atau berakhir.
+// userID adalah user yang memulai room atau participant terakhir yang keluar.
+func (mu *MessageUseCase) RecordRoom(room *models.CallRoom, userID uint, ended bool) (*models.ChatMessage, error) {
+	members, err := mu.groupRepo.MemberIDs(room.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := mu.conversationRepo.FindOrCreateGroup(room.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	summary := models.CallSummary{CallID: room.ID, Media: models.CallMediaAudio, Status: models.RoomActionStarted}
+	content := "Voice room started"
+	if ended {
+		summary.Status = models.CallStatusEnded
+		summary.Duration = int64(now.Sub(room.StartedAt).Seconds())
+		duration := time.Duration(summary.Duration) * time.Second
+		content = fmt.Sprintf("Voice room ended (%s)", formatCallDuration(duration))
+	}
+
+	groupID := room.GroupID
+	message := &models.ChatMessage{
+		Content:   content,
+		Type:      "system",
+		SenderID:  userID,
+		GroupID:   &groupID,
+		Call:      &summary,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := mu.deliver(conversation, members, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// callContent adalah teks pesan call untuk preview conversation dan client lama
+func callContent(summary models.CallSummary) string {
+	label := "Voice call"
+	if summary.Media == models.CallMediaVideo {
+		label = "Video call"
+	}
+
+	switch summary.Status {
+	case models.CallStatusMissed:
+		return "Missed " + strings.ToLower(label)
+	case models.CallStatusDeclined:
+		return label + " declined"
+	default:
+		duration := time.Duration(summary.Duration) * time.Second
+		return fmt.Sprintf("%s (%s)", label, formatCallDuration(duration))
+	}
+}
+
+// formatCallDuration menulis durasi sebagai m:ss
+func formatCallDuration(duration time.Duration) string {
+	return fmt.Sprintf("%d:%02d", int(duration.Minutes()), int(duration.Seconds())%60)
+}
+
+// deliver menyimpan pesan baru, memperbarui ringkasan conversation dan unread count,
+// lalu mengirim event message.created ke semua anggota conversation
+func (mu *MessageUseCase) deliver(conversation *models.Conversation, members []uint, message *models.ChatMessage) error {
+	if err := mu.messageRepo.Create(message); err != nil {
+		return err
+	}
+
+	recipients := exclude(members, message.SenderID)
+	if err := mu.conversationRepo.RecordMessage(conversation.ID, message, messagePreview(message), recipients); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conversationID := conversation.ID.Hex()
+	for _, recipientID := range recipients {
+		if err := config.Cache.IncrementUnreadCount(ctx, recipientID, conversationID); err != nil {
+			// counter Redis akan diperbaiki oleh rekonsiliasi unread
+			log.Printf("Failed to increment unread count for user %d: %v", recipientID, err)
+		}
+	}
+	config.Cache.InvalidateConversationCache(ctx, members...)
+
+	mu.publishMessage(members, models.EventMessageCreated, conversationID, message)
+	return nil
+}
+
+// publishMessage mengirim event pesan ke anggota conversation. Anggota grup yang memblokir
+// pengirim menerima salinan dengan SenderBlocked agar client bisa menyembunyikannya.
+func (mu *MessageUseCase) publishMessage(members []uint, eventType, conversationID string, message *models.ChatMessage) {
+	var blockerIDs []uint
+	if message.GroupID != nil {
+		var err error
+		if blockerIDs, err = mu.friendshipRepo.BlockerIDs(message.SenderID); err != nil {
+			log.Printf("Failed to get blockers of user %d: %v", message.SenderID, err)
+		}
+	}
+
+	others := make([]uint, 0, len(members))
+	blockers := []uint{}
+	for _, id := range members {
+		if containsID(blockerIDs, id) {
+			blockers = append(blockers, id)
+		} else {
+			others = append(others, id)
+		}
+	}
+	mu.publisher.PublishToUsers(others, models.NewEvent(eventType, models.MessageEvent{
+		ConversationID: conversationID,
+		Message:        *message,
+	}))
+
+	if len(blockers) > 0 {
+		hidden := *message
+		hidden.SenderBlocked = true
+		mu.publisher.PublishToUsers(blockers, models.NewEvent(eventType, models.MessageEvent{
+			ConversationID: conversationID,
+			Message:        hidden,
+		}))
+	}
+}
+
+// markBlockedSenders menandai pesan dari user yang diblokir userID
+func (mu *MessageUseCase) markBlockedSenders(userID uint, messages []models.ChatMessage) error {
+	blockedIDs, err := mu.friendshipRepo.BlockedIDs(userID)
+	if err != nil || len(blockedIDs) == 0 {
+		return err
+	}
+	for i := range messages {
+		messages[i].SenderBlocked = containsID(blockedIDs, messages[i].SenderID)
+	}
+	return nil
+}
+
+// resolveConversation memvalidasi tujuan pesan dan mengembalikan conversation beserta anggotanya
+func (mu *MessageUseCase) resolveConversation(userID uint, recipientID, groupID *uint) (*models.Conversation, []uint, error) {
+	if groupID != nil {
+		if _, _, err := mu.permissionUseCase.Authorize(*groupID, userID, models.PermissionSendMessages); err != nil {
+			return nil, nil, err
+		}
+		members, err := mu.groupRepo.MemberIDs(*groupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		conversation, err := mu.conversationRepo.FindOrCreateGroup(*groupID)
+		return conversation, members, err
+	}
+
+	if *recipientID == userID {
+		return nil, nil, fmt.Errorf("%w: cannot send a message to yourself", ErrInvalidInput)
+	}
+	if _, err := mu.userRepo.FindByID(*recipientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	blocked, err := mu.friendshipRepo.Blocked(userID, *recipientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blocked {
+		return nil, nil, ErrBlocked
+	}
+	if err := mu.privacyUseCase.CanMessage(userID, *recipientID); err != nil {
+		return nil, nil, err
+	}
+	conversation, err := mu.conversationRepo.FindOrCreateDM(userID, *recipientID)
+	return conversation, []uint{userID, *recipientID}, err
+}
+
+// Edit mengubah isi pesan milik user sendiri
+func (mu *MessageUseCase) Edit(userID uint, messageID, content string) (*models.ChatMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: message content is empty", ErrInvalidInput)
+	}
+
+	message, err := mu.findOwnMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	// mengedit pesan DM sama dengan mengirim ulang ke lawan bicara, jadi block tetap berlaku
+	if message.RecipientID != nil {
+		blocked, err := mu.friendshipRepo.Blocked(userID, *message.RecipientID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrBlocked
+		}
+	}
+
+	message.Content = content
+	message.IsEdited = true
+	message.UpdatedAt = time.Now().UTC()
+	if err := mu.messageRepo.Update(message); err != nil {
+		return nil, err
+	}
+
+	mu.publishUpdated(message)
+	return message, nil
+}
+
+// Delete menghapus pesan (soft delete, isi dikosongkan). Selain pesan milik sendiri, anggota
+// grup dengan permission delete_messages bisa menghapus pesan anggota lain di grup tersebut.
+func (mu *MessageUseCase) Delete(userID uint, messageID string) (*models.ChatMessage, error) {
+	message, err := mu.findMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userID {
+		if message.GroupID == nil {
+			return nil, ErrNotFound
+		}
+		_, _, err := mu.permissionUseCase.Authorize(*message.GroupID, userID, models.PermissionDeleteMessages)
+		if errors.Is(err, ErrNotMember) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	message.Content = ""
+	message.Attachments = nil
+	message.IsDeleted = true
+	message.UpdatedAt = now
+	message.DeletedAt = &now
+	if err := mu.messageRepo.Update(message); err != nil {
+		return nil, err
+	}
+
+	mu.publishUpdated(message)
+	return message, nil
+}
+
+func (mu *MessageUseCase) findOwnMessage(userID uint, messageID string) (*models.ChatMessage, error) {
+	message, err := mu.findMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userID {
+		return nil, ErrNotFound
+	}
+	return message, nil
+}
+
+// findMessage mengambil pesan yang belum dihapus
+func (mu *MessageUseCase) findMessage(messageID string) (*models.ChatMessage, error) {
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	message, err := mu.messageRepo.FindByID(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if message.IsDeleted {
+		return nil, fmt.Errorf("%w: message has been deleted", ErrInvalidInput)
+	}
+	return message, nil
+}
+
+// publishUpdated mengirim event message.updated ke semua participant conversation pesan.
+// Conversation pesan yang sudah ada selalu ada, jadi cukup dicari tanpa upsert.
+func (mu *MessageUseCase) publishUpdated(message *models.ChatMessage) {
+	var conversation *models.Conversation
+	var err error
+	if message.GroupID != nil {
+		conversation, err = mu.conversationRepo.FindByGroup(*message.GroupID)
+	} else if message.RecipientID != nil {
+		conversation, err = mu.conversationRepo.FindDM(message.SenderID, *message.RecipientID)
+	} else {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to resolve conversation of message %s: %v", message.ID.Hex(), err)
+		return
+	}
+
+	members, err := conversationMembers(mu.groupRepo, conversation)
+	if err != nil {
+		log.Printf("Failed to resolve members of conversation %s: %v", conversation.ID.Hex(), err)
+		return
+	}
+	mu.publishMessage(members, models.EventMessageUpdated, conversation.ID.Hex(), message)
+}
+
+// ListMessages mengambil riwayat pesan conversation, terbaru dulu, dengan cursor "before"
+func (mu *MessageUseCase) ListMessages(userID uint, conversationID, before string, limit int64) ([]models.ChatMessage, error) {
+	conversation, err := findMemberConversation(mu.conversationRepo, mu.permissionUseCase, userID, conversationID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := primitive.NilObjectID
+	if before != "" {
+		if cursor, err = primitive.ObjectIDFromHex(before); err != nil {
+			return nil, ErrInvalidID
+		}
+	}
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	messages, err := mu.messageRepo.FindPage(conversation, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := mu.markBlockedSenders(userID, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// messagePreview membuat teks singkat untuk daftar conversation
+func messagePreview(message *models.ChatMessage) string {
+	if message.Content != "" {
+		runes := []rune(message.Content)
+		if len(runes) > 100 {
+			return string(runes[:100]) + "…"
+		}
+		return message.Content
+	}
+	return "[" + message.Type + "]"
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Search mencari pesan di semua conversation yang diikuti user
+func (mu *MessageUseCase) Search(userID uint, conversationID string, filter models.MessageSearchFilter) ([]models.MessageSearchResult, int64, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, 0, fmt.Errorf("%w: search query is required", ErrInvalidInput)
+	}
+	page, limit := SearchPage(int(filter.Page), int(filter.Limit))
+	filter.Page, filter.Limit = int64(page), int64(limit)
+	if filter.Type != "" && !searchMessageTypes[filter.Type] {
+		return nil, 0, fmt.Errorf("%w: type must be text, image, file, audio, video, call or system", ErrInvalidInput)
+	}
+	if filter.Since != nil && filter.Until != nil && filter.Since.After(*filter.Until) {
+		return nil, 0, fmt.Errorf("%w: since must be before until", ErrInvalidInput)
+	}
+
+	if conversationID != "" {
+		conversation, err := findMemberConversation(mu.conversationRepo, mu.permissionUseCase, userID, conversationID, "")
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.Conversation = conversation
+	}
+
+	groupIDs, err := mu.groupRepo.GroupIDsForUser(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results, total, err := mu.messageRepo.Search(userID, groupIDs, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blockedIDs, err := mu.friendshipRepo.BlockedIDs(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	terms := searchTerms(filter.Query)
+	for i := range results {
+		results[i].Highlights = highlight(results[i].Content, terms)
+		results[i].SenderBlocked = containsID(blockedIDs, results[i].SenderID)
+	}
+	return results, total, nil
+}
+
+// searchTerms mengambil kata yang dicari dari query $text (tanpa negasi "-kata")
+func searchTerms(query string) []string {
+	terms := []string{}
+	for _, field := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		terms = append(terms, strings.ToLower(field))
+	}
+	return terms
+}
+
+// highlight mencari posisi setiap term di content (case-insensitive) dalam offset rune.
+// Kemunculan yang tumpang tindih atau bersebelahan digabung menjadi satu range.
+func highlight(content string, terms []string) []models.TextRange {
+	ranges := []models.TextRange{}
+	lower := strings.ToLower(content)
+	if len(lower) != len(content) {
+		// ToLower mengubah panjang byte (karakter khusus), offset tidak bisa dipetakan
+		return ranges
+	}
+
+	covered := make([]bool, len(content))
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		for offset := 0; offset < len(lower); {
+			index := strings.Index(lower[offset:], term)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			for i := start; i < start+len(term); i++ {
+				covered[i] = true
+			}
+			offset = start + 1
+		}
+	}
+
+	for start := 0; start < len(covered); start++ {
+		if !covered[start] {
+			continue
+		}
+		end := start
+		for end < len(covered) && covered[end] {
+			end++
+		}
+		ranges = append(ranges, models.TextRange{
+			Start: utf8.RuneCountInString(content[:start]),
+			End:   utf8.RuneCountInString(content[:end]),
+		})
+		start = end
+	}
+	return ranges
+}
@@ -0,0 +1,33 @@
+package usecases
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	// halaman dibatasi agar (page-1)*limit tidak overflow; halaman setinggi ini sudah pasti kosong
+	maxListPage = 10000
+)
+
+// ListPage menormalkan page dan limit daftar berhalaman (teman, grup, join request, dsb).
+// Controller memakainya untuk mengembalikan nilai yang benar-benar dipakai.
+func ListPage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if page > maxListPage {
+		page = maxListPage
+	}
+	if limit < 1 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return page, limit
+}
+
+// SearchPage sama dengan ListPage, dengan limit yang lebih kecil untuk hasil pencarian pesan
+func SearchPage(page, limit int) (int, int) {
+	page, limit = ListPage(page, limit)
+	return page, min(limit, maxSearchLimit)
+}
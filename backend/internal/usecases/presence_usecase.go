@@ -0,0 +1,273 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const presenceSweepBatchSize = 200
+
+// Urutan daftar teman
+const (
+	FriendSortOnline = "online" // online dulu, lalu away, lalu offline; masing-masing urut nama
+	FriendSortName   = "name"
+)
+
+var presenceRank = map[string]int{"online": 0, "away": 1, "offline": 2}
+
+type PresenceConfig struct {
+	HeartbeatTTL time.Duration // heartbeat device berlaku selama ini
+	AwayAfter    time.Duration // user tanpa aktivitas selama ini menjadi away
+}
+
+type PresenceUseCase struct {
+	presenceRepo   repositories.PresenceRepository
+	userRepo       repositories.UserRepository
+	friendshipRepo repositories.FriendshipRepository
+	privacyRepo    repositories.PrivacyRepository
+	publisher      EventPublisher
+	config         PresenceConfig
+}
+
+func NewPresenceUseCase(presenceRepo repositories.PresenceRepository, userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyRepo repositories.PrivacyRepository, publisher EventPublisher, config PresenceConfig) *PresenceUseCase {
+	return &PresenceUseCase{
+		presenceRepo:   presenceRepo,
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		privacyRepo:    privacyRepo,
+		publisher:      publisher,
+		config:         config,
+	}
+}
+
+// DeviceConnected dipanggil saat device membuka koneksi realtime, dianggap heartbeat pertama
+func (pu *PresenceUseCase) DeviceConnected(userID uint, deviceID string) {
+	if err := pu.Heartbeat(userID, deviceID, true); err != nil {
+		log.Printf("Failed to record presence of user %d: %v", userID, err)
+	}
+}
+
+// DeviceAlive dipanggil saat koneksi realtime device mengirim pong atau frame lain,
+// sehingga koneksi yang masih hidup tidak dianggap offline walau client tidak mengirim heartbeat
+func (pu *PresenceUseCase) DeviceAlive(userID uint, deviceID string, active bool) {
+	if err := pu.Heartbeat(userID, deviceID, active); err != nil {
+		log.Printf("Failed to refresh presence of user %d: %v", userID, err)
+	}
+}
+
+// DeviceDisconnected dipanggil saat koneksi realtime device ditutup.
+// User langsung offline jika tidak ada device lain yang masih heartbeat.
+func (pu *PresenceUseCase) DeviceDisconnected(userID uint, deviceID string) {
+	transition, err := pu.presenceRepo.RemoveDevice(userID, deviceID, pu.config.AwayAfter)
+	if err != nil {
+		log.Printf("Failed to remove presence device of user %d: %v", userID, err)
+		return
+	}
+	pu.apply(userID, transition)
+}
+
+// Heartbeat memperpanjang presence device. active berarti user berinteraksi dengan
+// aplikasi sejak heartbeat sebelumnya (aplikasi di background mengirim false).
+func (pu *PresenceUseCase) Heartbeat(userID uint, deviceID string, active bool) error {
+	if deviceID == "" || len(deviceID) > 64 {
+		return ErrInvalidInput
+	}
+
+	transition, err := pu.presenceRepo.Touch(userID, deviceID, pu.config.HeartbeatTTL, active, pu.config.AwayAfter)
+	if err != nil {
+		return err
+	}
+	pu.apply(userID, transition)
+	return nil
+}
+
+// apply menyimpan status baru ke MySQL dan mengirimkannya ke teman user jika status berubah
+func (pu *PresenceUseCase) apply(userID uint, transition repositories.PresenceTransition) {
+	if !transition.Changed() {
+		return
+	}
+
+	lastSeen := transition.LastActive
+	if transition.Status == "online" || lastSeen.IsZero() {
+		lastSeen = time.Now().UTC()
+	}
+	if err := pu.userRepo.UpdatePresence(userID, transition.Status, lastSeen); err != nil {
+		log.Printf("Failed to persist presence of user %d: %v", userID, err)
+	}
+
+	settings, err := pu.privacyRepo.Find(userID)
+	if err != nil {
+		log.Printf("Failed to get privacy settings of user %d: %v", userID, err)
+		return
+	}
+	// teman tidak diberi tahu transisi user yang menyembunyikan status online dari teman
+	if !models.PrivacyAllows(settings.OnlineStatus, true) {
+		return
+	}
+	pu.publish(userID, *settings, transition.Status, lastSeen)
+}
+
+// publish mengirim presence user ke temannya sesuai yang boleh dilihat teman
+func (pu *PresenceUseCase) publish(userID uint, settings models.PrivacySettings, status string, lastSeen time.Time) {
+	friendIDs, err := pu.friendshipRepo.FriendIDs(userID)
+	if err != nil {
+		log.Printf("Failed to get friends of user %d: %v", userID, err)
+		return
+	}
+
+	event := models.PresenceEvent{UserID: userID}
+	status, lastSeen = settings.VisiblePresence(status, lastSeen, true)
+	event.Status = status
+	if !lastSeen.IsZero() {
+		event.LastSeen = &lastSeen
+	}
+	pu.publisher.PublishToUsers(friendIDs, models.NewEvent(models.EventPresence, event))
+}
+
+// Republish mengirim ulang presence user ke temannya setelah pengaturan privasi berubah
+func (pu *PresenceUseCase) Republish(userID uint, settings models.PrivacySettings) {
+	user, err := pu.userRepo.FindByID(userID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", userID, err)
+		return
+	}
+	users := []models.User{*user}
+	if err := pu.current(users); err != nil {
+		log.Printf("Failed to get presence of user %d: %v", userID, err)
+		return
+	}
+	pu.publish(userID, settings, users[0].Status, users[0].LastSeen)
+}
+
+// current mengisi status dan last seen users dengan presence terkini dari Redis dalam
+// satu round trip. Last seen user online adalah waktu sekarang, dan last seen dari MySQL
+// tetap dipakai jika aktivitas terakhir sudah kedaluwarsa di Redis.
+func (pu *PresenceUseCase) current(users []models.User) error {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	states, err := pu.presenceRepo.States(ids)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for i, state := range states {
+		users[i].Status = state.Status
+		switch {
+		case state.Status == "online":
+			users[i].LastSeen = now
+		case !state.LastActive.IsZero():
+			users[i].LastSeen = state.LastActive
+		}
+	}
+	return nil
+}
+
+// Sweep mengevaluasi ulang semua user yang tidak offline untuk mendeteksi heartbeat yang
+// kedaluwarsa dan user yang menjadi away, lalu mengembalikan jumlah transisi.
+func (pu *PresenceUseCase) Sweep() (int, error) {
+	transitions := 0
+	var cursor uint64
+
+	for {
+		userIDs, next, err := pu.presenceRepo.TrackedUsers(cursor, presenceSweepBatchSize)
+		if err != nil {
+			return transitions, err
+		}
+
+		if len(userIDs) > 0 {
+			results, err := pu.presenceRepo.Evaluate(userIDs, pu.config.AwayAfter)
+			if err != nil {
+				return transitions, err
+			}
+			for i, transition := range results {
+				if transition.Changed() {
+					pu.apply(userIDs[i], transition)
+					transitions++
+				}
+			}
+		}
+
+		if next == 0 {
+			return transitions, nil
+		}
+		cursor = next
+	}
+}
+
+// StartSweeper menjalankan Sweep secara berkala di background
+func (pu *PresenceUseCase) StartSweeper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := pu.Sweep(); err != nil {
+				log.Printf("Presence sweep failed: %v", err)
+			}
+		}
+	}()
+}
+
+// ListFriends mengambil teman user beserta presence terkini dari Redis. Status online dan
+// last seen teman yang menyembunyikannya lewat pengaturan privasi dikosongkan.
+func (pu *PresenceUseCase) ListFriends(userID uint, sortBy string, page, limit int) ([]models.FriendPresence, int, error) {
+	if sortBy == "" {
+		sortBy = FriendSortOnline
+	}
+	if sortBy != FriendSortOnline && sortBy != FriendSortName {
+		return nil, 0, fmt.Errorf("%w: sort must be online or name", ErrInvalidInput)
+	}
+	page, limit = ListPage(page, limit)
+
+	friendIDs, err := pu.friendshipRepo.FriendIDs(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := pu.userRepo.FindByIDs(friendIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := pu.current(users); err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	privacy, err := pu.privacyRepo.FindMany(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	friends := make([]models.FriendPresence, len(users))
+	for i, user := range users {
+		user.Status, user.LastSeen = privacy[user.ID].VisiblePresence(user.Status, user.LastSeen, true)
+		friend := models.FriendPresence{User: user, Status: user.Status}
+		if !user.LastSeen.IsZero() {
+			lastSeen := user.LastSeen
+			friend.LastSeen = &lastSeen
+		}
+		friends[i] = friend
+	}
+
+	sort.SliceStable(friends, func(i, j int) bool {
+		if sortBy == FriendSortOnline && friends[i].Status != friends[j].Status {
+			return presenceRank[friends[i].Status] < presenceRank[friends[j].Status]
+		}
+		return strings.ToLower(displayName(&friends[i].User)) < strings.ToLower(displayName(&friends[j].User))
+	})
+
+	total := len(friends)
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+	return friends[start:end], total, nil
+}
@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var privacyAudiences = map[string]bool{models.PrivacyEveryone: true, models.PrivacyFriends: true, models.PrivacyNobody: true}
+
+// PrivacyInput adalah perubahan pengaturan privasi, field nil tidak diubah
+type PrivacyInput struct {
+	DirectMessages *string `json:"direct_messages"`
+	OnlineStatus   *string `json:"online_status"`
+	LastSeen       *string `json:"last_seen"`
+	GroupAdd       *string `json:"group_add"`
+	Searchable     *bool   `json:"searchable"`
+}
+
+type PrivacyUseCase struct {
+	privacyRepo     repositories.PrivacyRepository
+	friendshipRepo  repositories.FriendshipRepository
+	presenceUseCase *PresenceUseCase
+}
+
+func NewPrivacyUseCase(privacyRepo repositories.PrivacyRepository, friendshipRepo repositories.FriendshipRepository, presenceUseCase *PresenceUseCase) *PrivacyUseCase {
+	return &PrivacyUseCase{
+		privacyRepo:     privacyRepo,
+		friendshipRepo:  friendshipRepo,
+		presenceUseCase: presenceUseCase,
+	}
+}
+
+func (pu *PrivacyUseCase) Get(userID uint) (*models.PrivacySettings, error) {
+	return pu.privacyRepo.Find(userID)
+}
+
+// Update mengubah pengaturan privasi user. Jika visibilitas presence berubah, teman user
+// langsung menerima presence yang terlihat menurut pengaturan baru.
+func (pu *PrivacyUseCase) Update(userID uint, input PrivacyInput) (*models.PrivacySettings, error) {
+	settings, err := pu.privacyRepo.Find(userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := *settings
+
+	audiences := []struct {
+		name  string
+		value *string
+		field *string
+	}{
+		{"direct_messages", input.DirectMessages, &settings.DirectMessages},
+		{"online_status", input.OnlineStatus, &settings.OnlineStatus},
+		{"last_seen", input.LastSeen, &settings.LastSeen},
+		{"group_add", input.GroupAdd, &settings.GroupAdd},
+	}
+	for _, audience := range audiences {
+		if audience.value == nil {
+			continue
+		}
+		if !privacyAudiences[*audience.value] {
+			return nil, fmt.Errorf("%w: %s must be everyone, friends or nobody", ErrInvalidInput, audience.name)
+		}
+		*audience.field = *audience.value
+	}
+	if input.Searchable != nil {
+		settings.Searchable = *input.Searchable
+	}
+
+	if err := pu.privacyRepo.Save(settings); err != nil {
+		return nil, err
+	}
+	if settings.OnlineStatus != previous.OnlineStatus || settings.LastSeen != previous.LastSeen {
+		pu.presenceUseCase.Republish(userID, *settings)
+	}
+	return settings, nil
+}
+
+// allows memeriksa apakah actorID termasuk audience yang dipilih ownerID
+func (pu *PrivacyUseCase) allows(ownerID, actorID uint, audience string) (bool, error) {
+	if audience != models.PrivacyFriends {
+		return models.PrivacyAllows(audience, false), nil
+	}
+
+	friendship, err := pu.friendshipRepo.FindBetween(ownerID, actorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return friendship.Status == models.FriendshipAccepted, nil
+}
+
+// CanMessage memastikan senderID boleh mengirim DM ke recipientID
+func (pu *PrivacyUseCase) CanMessage(senderID, recipientID uint) error {
+	settings, err := pu.privacyRepo.Find(recipientID)
+	if err != nil {
+		return err
+	}
+	allowed, err := pu.allows(recipientID, senderID, settings.DirectMessages)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: this user does not accept direct messages from you", ErrRestricted)
+	}
+	return nil
+}
+
+// CanAddToGroup memastikan actorID boleh menambahkan targetID ke grup
+func (pu *PrivacyUseCase) CanAddToGroup(actorID, targetID uint) error {
+	if actorID == targetID {
+		return nil
+	}
+	settings, err := pu.privacyRepo.Find(targetID)
+	if err != nil {
+		return err
+	}
+	allowed, err := pu.allows(targetID, actorID, settings.GroupAdd)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: this user cannot be added to groups by you", ErrRestricted)
+	}
+	return nil
+}
+
+// FilterPresence menyembunyikan status online dan last seen users yang tidak boleh
+// dilihat viewerID menurut pengaturan privasi masing-masing, atau yang memblokir viewerID
+func (pu *PrivacyUseCase) FilterPresence(viewerID uint, users []models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	settings, err := pu.privacyRepo.FindMany(ids)
+	if err != nil {
+		return err
+	}
+	friendIDs, err := pu.friendshipRepo.FriendIDs(viewerID)
+	if err != nil {
+		return err
+	}
+	blockerIDs, err := pu.friendshipRepo.BlockerIDs(viewerID)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if users[i].ID == viewerID {
+			continue
+		}
+		if containsID(blockerIDs, users[i].ID) {
+			users[i].HidePresence()
+			continue
+		}
+		users[i].Status, users[i].LastSeen = settings[users[i].ID].VisiblePresence(users[i].Status, users[i].LastSeen, containsID(friendIDs, users[i].ID))
+	}
+	return nil
+}
+
+// filterPresenceOf menjalankan FilterPresence pada user yang tertanam di data lain
+// (anggota grup, pembuat invite, requester join request)
+func (pu *PrivacyUseCase) filterPresenceOf(viewerID uint, users []*models.User) error {
+	copies := make([]models.User, len(users))
+	for i, user := range users {
+		copies[i] = *user
+	}
+	if err := pu.FilterPresence(viewerID, copies); err != nil {
+		return err
+	}
+	for i, user := range users {
+		user.Status, user.LastSeen = copies[i].Status, copies[i].LastSeen
+	}
+	return nil
+}
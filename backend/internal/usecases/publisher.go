@@ -0,0 +1,15 @@
+package usecases
+
+import "echo-chat-app-backend/internal/models"
+
+// EventPublisher mengirim event realtime ke user tertentu.
+// Implementasinya disediakan oleh transport realtime di layer delivery.
+type EventPublisher interface {
+	PublishToUsers(userIDs []uint, event models.Event)
+}
+
+// SessionCloser menutup semua koneksi realtime milik user di semua instance,
+// misalnya saat akunnya dihapus
+type SessionCloser interface {
+	CloseUser(userID uint)
+}
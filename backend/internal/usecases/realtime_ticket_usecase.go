@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/repositories"
+	"fmt"
+	"time"
+)
+
+type RealtimeTicketUseCase struct {
+	ticketRepo repositories.RealtimeTicketRepository
+	ttl        time.Duration
+}
+
+func NewRealtimeTicketUseCase(ticketRepo repositories.RealtimeTicketRepository, ttl time.Duration) *RealtimeTicketUseCase {
+	return &RealtimeTicketUseCase{ticketRepo: ticketRepo, ttl: ttl}
+}
+
+// Issue membuat ticket sekali pakai untuk membuka /ws atau /events
+func (tu *RealtimeTicketUseCase) Issue(userID uint) (string, time.Time, error) {
+	ticket, err := randomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := tu.ticketRepo.Create(ticket, userID, tu.ttl); err != nil {
+		return "", time.Time{}, err
+	}
+	return ticket, time.Now().UTC().Add(tu.ttl), nil
+}
+
+// Redeem menukar ticket dengan user pemiliknya. Ticket langsung hangus setelah dipakai.
+func (tu *RealtimeTicketUseCase) Redeem(ticket string) (uint, error) {
+	if ticket == "" {
+		return 0, fmt.Errorf("%w: ticket is required", ErrInvalidInput)
+	}
+	userID, ok, err := tu.ticketRepo.Consume(ticket)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: ticket is invalid or expired", ErrNotFound)
+	}
+	return userID, nil
+}
@@ -0,0 +1,313 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RoomConfig struct {
+	MaxParticipants int // batas participant, setiap pasangan participant punya koneksi WebRTC sendiri
+}
+
+// RoomStateInput adalah perubahan state media participant, field nil tidak diubah
+type RoomStateInput struct {
+	Muted      *bool `json:"muted"`
+	Video      *bool `json:"video"`
+	HandRaised *bool `json:"hand_raised"`
+}
+
+type RoomUseCase struct {
+	roomRepo          repositories.RoomRepository
+	groupRepo         repositories.GroupRepository
+	permissionUseCase *GroupPermissionUseCase
+	messageUseCase    *MessageUseCase
+	publisher         EventPublisher
+	config            RoomConfig
+}
+
+func NewRoomUseCase(roomRepo repositories.RoomRepository, groupRepo repositories.GroupRepository, permissionUseCase *GroupPermissionUseCase, messageUseCase *MessageUseCase, publisher EventPublisher, config RoomConfig) *RoomUseCase {
+	return &RoomUseCase{
+		roomRepo:          roomRepo,
+		groupRepo:         groupRepo,
+		permissionUseCase: permissionUseCase,
+		messageUseCase:    messageUseCase,
+		publisher:         publisher,
+		config:            config,
+	}
+}
+
+// members memastikan user adalah anggota grup dan mengembalikan semua anggotanya
+func (ru *RoomUseCase) members(userID, groupID uint) ([]uint, error) {
+	if _, _, err := ru.permissionUseCase.Authorize(groupID, userID, ""); err != nil {
+		return nil, err
+	}
+	return ru.groupRepo.MemberIDs(groupID)
+}
+
+// Get mengembalikan room yang sedang terbuka di grup
+func (ru *RoomUseCase) Get(userID, groupID uint) (*models.CallRoom, error) {
+	if _, err := ru.members(userID, groupID); err != nil {
+		return nil, err
+	}
+
+	room, err := ru.roomRepo.Find(groupID)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return room, err
+}
+
+// Create membuka room baru di grup dengan user sebagai participant pertama
+func (ru *RoomUseCase) Create(userID uint, deviceID string, groupID uint) (*models.CallRoom, error) {
+	return ru.join(userID, deviceID, groupID, true)
+}
+
+// Join masuk ke room yang sedang terbuka di grup. User yang masih berada di room grup lain
+// dikeluarkan dari room tersebut lebih dulu.
+func (ru *RoomUseCase) Join(userID uint, deviceID string, groupID uint) (*models.CallRoom, error) {
+	return ru.join(userID, deviceID, groupID, false)
+}
+
+func (ru *RoomUseCase) join(userID uint, deviceID string, groupID uint, create bool) (*models.CallRoom, error) {
+	if deviceID == "" || len(deviceID) > 64 {
+		return nil, fmt.Errorf("%w: device_id is required", ErrInvalidInput)
+	}
+	members, err := ru.members(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := ru.roomRepo.RoomOfUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if current != 0 && current != groupID {
+		if err := ru.leave(current, userID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	room := &models.CallRoom{ID: id, GroupID: groupID, StartedBy: userID, StartedAt: now}
+	participant := models.RoomParticipant{UserID: userID, DeviceID: deviceID, JoinedAt: now}
+
+	result, err := ru.roomRepo.Join(room, participant, ru.config.MaxParticipants, create)
+	if err != nil {
+		return nil, err
+	}
+	switch result {
+	case repositories.RoomFull:
+		return nil, fmt.Errorf("%w: room is full", ErrConflict)
+	case repositories.RoomAlreadyOpen:
+		return nil, fmt.Errorf("%w: a room is already open in this group, join it instead", ErrConflict)
+	case repositories.RoomNotOpen:
+		return nil, ErrNotFound
+	}
+
+	if room, err = ru.roomRepo.Find(groupID); err != nil {
+		return nil, err
+	}
+
+	action := models.RoomActionJoined
+	if result == repositories.RoomStarted {
+		action = models.RoomActionStarted
+	}
+	ru.publish(members, room, action, userID)
+	if result == repositories.RoomStarted {
+		if _, err := ru.messageUseCase.RecordRoom(room, userID, false); err != nil {
+			log.Printf("Failed to record start of room %s: %v", room.ID, err)
+		}
+	}
+	return room, nil
+}
+
+// Leave mengeluarkan user dari room grup, room ditutup saat participant terakhir keluar
+func (ru *RoomUseCase) Leave(userID, groupID uint) error {
+	if _, err := ru.members(userID, groupID); err != nil {
+		return err
+	}
+	return ru.leave(groupID, userID, "")
+}
+
+// DeviceDisconnected dipanggil saat koneksi realtime device ditutup. Participant yang
+// kehilangan koneksi tidak bisa menerima signal lagi, jadi dikeluarkan dari room.
+func (ru *RoomUseCase) DeviceDisconnected(userID uint, deviceID string) {
+	groupID, err := ru.roomRepo.RoomOfUser(userID)
+	if err != nil {
+		log.Printf("Failed to get room of user %d: %v", userID, err)
+		return
+	}
+	if groupID == 0 {
+		return
+	}
+	if err := ru.leave(groupID, userID, deviceID); err != nil {
+		log.Printf("Failed to remove user %d from room of group %d: %v", userID, groupID, err)
+	}
+}
+
+// MemberLeft dipanggil saat user keluar atau dikeluarkan dari grup. User yang bukan anggota
+// lagi tidak boleh tetap berada di room grup, jadi dikeluarkan dari room.
+func (ru *RoomUseCase) MemberLeft(groupID, userID uint) {
+	if err := ru.leave(groupID, userID, ""); err != nil {
+		log.Printf("Failed to remove user %d from room of group %d: %v", userID, groupID, err)
+	}
+}
+
+// GroupDeleted dipanggil saat grup dihapus. Room grup langsung ditutup dan participant-nya
+// diberi tahu; pesan system tidak dicatat karena percakapan grup ikut terhapus.
+func (ru *RoomUseCase) GroupDeleted(groupID uint) {
+	room, err := ru.roomRepo.Close(groupID)
+	if errors.Is(err, redis.Nil) {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to close room of group %d: %v", groupID, err)
+		return
+	}
+
+	participants := make([]uint, 0, len(room.Participants))
+	for _, participant := range room.Participants {
+		participants = append(participants, participant.UserID)
+	}
+	room.Participants = []models.RoomParticipant{}
+	ru.publish(participants, room, models.RoomActionEnded, 0)
+}
+
+// leave mengeluarkan user lalu mengirim event left, atau ended beserta pesan system jika room tutup
+func (ru *RoomUseCase) leave(groupID, userID uint, deviceID string) error {
+	left, ended, err := ru.roomRepo.Leave(groupID, userID, deviceID)
+	if err != nil || !left {
+		return err
+	}
+
+	members, err := ru.groupRepo.MemberIDs(groupID)
+	if err != nil {
+		return err
+	}
+
+	if ended != nil {
+		ended.Participants = []models.RoomParticipant{}
+		ru.publish(members, ended, models.RoomActionEnded, userID)
+		if _, err := ru.messageUseCase.RecordRoom(ended, userID, true); err != nil {
+			log.Printf("Failed to record end of room %s: %v", ended.ID, err)
+		}
+		return nil
+	}
+
+	room, err := ru.roomRepo.Find(groupID)
+	if errors.Is(err, redis.Nil) {
+		// participant lain keluar bersamaan dan sudah mengirim event ended
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ru.publish(members, room, models.RoomActionLeft, userID)
+	return nil
+}
+
+// UpdateState mengubah state mute, video, atau angkat tangan participant
+func (ru *RoomUseCase) UpdateState(userID, groupID uint, input RoomStateInput) (*models.CallRoom, error) {
+	members, err := ru.members(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := ru.roomRepo.UpdateParticipant(groupID, userID, func(participant *models.RoomParticipant) {
+		if input.Muted != nil {
+			participant.Muted = *input.Muted
+		}
+		if input.Video != nil {
+			participant.Video = *input.Video
+		}
+		if input.HandRaised != nil {
+			participant.HandRaised = *input.HandRaised
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: user is not in the room", ErrNotParticipant)
+	}
+
+	room, err := ru.roomRepo.Find(groupID)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	ru.publish(members, room, models.RoomActionUpdated, userID)
+	return room, nil
+}
+
+// Signal merelay signal WebRTC dari device participant ke device participant lain di room yang
+// sama. Pengirim harus masih menjadi anggota grup.
+func (ru *RoomUseCase) Signal(userID uint, deviceID string, groupID, toUserID uint, signalType string, payload json.RawMessage) error {
+	if !callSignalTypes[signalType] {
+		return fmt.Errorf("%w: signal type must be offer, answer or ice", ErrInvalidInput)
+	}
+	if len(payload) == 0 || len(payload) > maxSignalSize {
+		return fmt.Errorf("%w: signal payload is empty or too large", ErrInvalidInput)
+	}
+	if _, _, err := ru.permissionUseCase.Authorize(groupID, userID, ""); err != nil {
+		return err
+	}
+
+	room, err := ru.roomRepo.Find(groupID)
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var from, to *models.RoomParticipant
+	for i := range room.Participants {
+		switch room.Participants[i].UserID {
+		case userID:
+			from = &room.Participants[i]
+		case toUserID:
+			to = &room.Participants[i]
+		}
+	}
+	if from == nil || from.DeviceID != deviceID {
+		return fmt.Errorf("%w: user is not in the room on this device", ErrNotParticipant)
+	}
+	if to == nil {
+		return fmt.Errorf("%w: receiver is not in the room", ErrNotFound)
+	}
+
+	ru.publisher.PublishToUsers([]uint{toUserID}, models.NewEvent(models.EventRoomSignal, models.RoomSignalEvent{
+		GroupID:    groupID,
+		RoomID:     room.ID,
+		FromUserID: userID,
+		FromDevice: deviceID,
+		ToDevice:   to.DeviceID,
+		Type:       signalType,
+		Payload:    payload,
+	}))
+	return nil
+}
+
+// publish mengirim perubahan room ke semua anggota grup, termasuk yang belum join
+func (ru *RoomUseCase) publish(members []uint, room *models.CallRoom, action string, userID uint) {
+	ru.publisher.PublishToUsers(members, models.NewEvent(models.EventRoom, models.RoomEvent{
+		GroupID: room.GroupID,
+		Action:  action,
+		UserID:  userID,
+		Room:    *room,
+	}))
+}
@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"log"
+	"time"
+)
+
+const (
+	defaultSuggestionLimit = 20
+	maxSuggestionLimit     = 50
+
+	// Skor kandidat adalah mutualFriendWeight * jumlah teman bersama + jumlah grup yang sama,
+	// sehingga teman bersama selalu menentukan peringkat lebih dulu
+	mutualFriendWeight = 1000
+	maxSharedGroups    = mutualFriendWeight - 1
+)
+
+type SuggestionConfig struct {
+	CacheTTL time.Duration // cache saran dibangun ulang penuh setelah ini (misalnya untuk perubahan grup)
+}
+
+type SuggestionUseCase struct {
+	suggestionRepo repositories.SuggestionRepository
+	friendshipRepo repositories.FriendshipRepository
+	groupRepo      repositories.GroupRepository
+	userRepo       repositories.UserRepository
+	privacyUseCase *PrivacyUseCase
+	config         SuggestionConfig
+}
+
+func NewSuggestionUseCase(suggestionRepo repositories.SuggestionRepository, friendshipRepo repositories.FriendshipRepository, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, privacyUseCase *PrivacyUseCase, config SuggestionConfig) *SuggestionUseCase {
+	return &SuggestionUseCase{
+		suggestionRepo: suggestionRepo,
+		friendshipRepo: friendshipRepo,
+		groupRepo:      groupRepo,
+		userRepo:       userRepo,
+		privacyUseCase: privacyUseCase,
+		config:         config,
+	}
+}
+
+// List mengambil saran pertemanan user. Teman, user yang punya permintaan pending dengan
+// user, dan block dari kedua arah disaring saat dibaca, sehingga cache cukup berisi jumlah
+// teman bersama dan grup yang sama.
+func (su *SuggestionUseCase) List(userID uint, limit int) ([]models.FriendSuggestion, error) {
+	if limit < 1 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	relatedIDs, err := su.friendshipRepo.RelatedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// kandidat yang tersaring paling banyak sebanyak relatedIDs
+	count := limit + len(relatedIDs)
+	ids, scores, found, err := su.suggestionRepo.Top(userID, count)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if err := su.rebuild(userID); err != nil {
+			return nil, err
+		}
+		if ids, scores, _, err = su.suggestionRepo.Top(userID, count); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := make([]uint, 0, limit)
+	ranked := make(map[uint]float64, limit)
+	for i, id := range ids {
+		if len(candidates) == limit {
+			break
+		}
+		if containsID(relatedIDs, id) {
+			continue
+		}
+		candidates = append(candidates, id)
+		ranked[id] = scores[i]
+	}
+
+	users, err := su.userRepo.FindByIDs(candidates)
+	if err != nil {
+		return nil, err
+	}
+	if err := su.privacyUseCase.FilterPresence(userID, users); err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	suggestions := make([]models.FriendSuggestion, 0, len(candidates))
+	for _, id := range candidates {
+		user, ok := byID[id]
+		if !ok {
+			continue
+		}
+		score := int(ranked[id])
+		suggestions = append(suggestions, models.FriendSuggestion{
+			User:          user,
+			MutualFriends: score / mutualFriendWeight,
+			SharedGroups:  score % mutualFriendWeight,
+		})
+	}
+	return suggestions, nil
+}
+
+// rebuild menghitung ulang semua kandidat user dari teman-dari-teman dan sesama anggota grup
+func (su *SuggestionUseCase) rebuild(userID uint) error {
+	friendIDs, err := su.friendshipRepo.FriendIDs(userID)
+	if err != nil {
+		return err
+	}
+	edges, err := su.friendshipRepo.FriendEdges(friendIDs)
+	if err != nil {
+		return err
+	}
+	sharedGroups, err := su.groupRepo.SharedGroupCounts(userID)
+	if err != nil {
+		return err
+	}
+
+	friends := make(map[uint]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		friends[id] = true
+	}
+
+	scores := map[uint]float64{}
+	for _, edge := range edges {
+		if friends[edge.UserID] && edge.FriendID != userID {
+			scores[edge.FriendID] += mutualFriendWeight
+		}
+		if friends[edge.FriendID] && edge.UserID != userID {
+			scores[edge.UserID] += mutualFriendWeight
+		}
+	}
+	for candidateID, shared := range sharedGroups {
+		scores[candidateID] += float64(min(shared, maxSharedGroups))
+	}
+	return su.suggestionRepo.Save(userID, scores, su.config.CacheTTL)
+}
+
+// FriendshipChanged memperbarui cache saran yang terdampak saat userA dan userB menjadi
+// teman (friends true) atau berhenti berteman. Setiap teman userA mendapat atau kehilangan
+// satu teman bersama dengan userB, begitu juga sebaliknya. Cache yang belum ada tidak
+// disentuh karena akan dibangun lengkap saat dibaca.
+func (su *SuggestionUseCase) FriendshipChanged(userA, userB uint, friends bool) {
+	delta := float64(mutualFriendWeight)
+	if !friends {
+		delta = -delta
+	}
+
+	changes := []repositories.SuggestionChange{}
+	for _, pair := range [][2]uint{{userA, userB}, {userB, userA}} {
+		user, other := pair[0], pair[1]
+		friendIDs, err := su.friendshipRepo.FriendIDs(user)
+		if err != nil {
+			log.Printf("Failed to update friend suggestions around user %d: %v", user, err)
+			return
+		}
+		for _, friendID := range friendIDs {
+			if friendID == other {
+				continue
+			}
+			changes = append(changes,
+				repositories.SuggestionChange{UserID: friendID, CandidateID: other, Delta: delta},
+				repositories.SuggestionChange{UserID: other, CandidateID: friendID, Delta: delta},
+			)
+		}
+	}
+
+	if err := su.suggestionRepo.Apply(changes); err != nil {
+		log.Printf("Failed to update friend suggestions around users %d and %d: %v", userA, userB, err)
+	}
+}
@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	syncTokenVersion = 1
+	defaultSyncLimit = 100
+	maxSyncLimit     = 500
+
+	// syncSettleDelay menahan perubahan yang sangat baru ke page berikutnya. Timestamp dibuat
+	// sebelum data tersimpan, jadi baris dengan updated_at lebih awal bisa muncul belakangan.
+	syncSettleDelay = 2 * time.Second
+)
+
+type SyncUseCase struct {
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+	friendshipRepo   repositories.FriendshipRepository
+	groupRepo        repositories.GroupRepository
+	userRepo         repositories.UserRepository
+	privacyUseCase   *PrivacyUseCase
+}
+
+func NewSyncUseCase(messageRepo repositories.MessageRepository, conversationRepo repositories.ConversationRepository, friendshipRepo repositories.FriendshipRepository, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, privacyUseCase *PrivacyUseCase) *SyncUseCase {
+	return &SyncUseCase{
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		friendshipRepo:   friendshipRepo,
+		groupRepo:        groupRepo,
+		userRepo:         userRepo,
+		privacyUseCase:   privacyUseCase,
+	}
+}
+
+func encodeSyncToken(token models.SyncToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeSyncToken membaca token dari client, token kosong berarti sinkronisasi dari awal
+func decodeSyncToken(value string) (models.SyncToken, error) {
+	token := models.SyncToken{Version: syncTokenVersion}
+	if value == "" {
+		return token, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &token) != nil || token.Version != syncTokenVersion {
+		return token, ErrInvalidInput
+	}
+	return token, nil
+}
+
+// mongoCursor dan mysqlCursor membaca ID cursor sesuai jenis primary key sumber datanya
+func mongoCursor(cursor models.SyncCursor) (primitive.ObjectID, error) {
+	if cursor.ID == "" {
+		return primitive.NilObjectID, nil
+	}
+	id, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return id, ErrInvalidInput
+	}
+	return id, nil
+}
+
+func mysqlCursor(cursor models.SyncCursor) (uint, error) {
+	if cursor.ID == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(cursor.ID, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInput
+	}
+	return uint(id), nil
+}
+
+// Sync mengembalikan semua perubahan sejak token untuk user, maksimal limit item per jenis data.
+// Setiap jenis data punya cursor sendiri di dalam token, sehingga page berikutnya melanjutkan
+// tepat dari item terakhir yang sudah dikirim.
+func (su *SyncUseCase) Sync(userID uint, tokenValue string, limit int) (*models.SyncPage, error) {
+	token, err := decodeSyncToken(tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = defaultSyncLimit
+	}
+	if limit > maxSyncLimit {
+		limit = maxSyncLimit
+	}
+
+	groupIDs, err := su.groupRepo.GroupIDsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := time.Now().UTC().Add(-syncSettleDelay)
+	page := &models.SyncPage{}
+
+	// MongoDB: pesan dan ringkasan conversation
+	messageAfter, err := mongoCursor(token.Messages)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := su.messageRepo.FindChangedSince(userID, groupIDs, token.Messages.UpdatedAt, messageAfter, until, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		token.Messages = models.SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID.Hex()}
+	}
+	blockedIDs, err := su.friendshipRepo.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].SenderBlocked = containsID(blockedIDs, messages[i].SenderID)
+	}
+	page.Changes.Messages = messages
+	page.HasMore = page.HasMore || len(messages) == limit
+
+	conversationAfter, err := mongoCursor(token.Conversations)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := su.conversationRepo.FindChangedSince(userID, groupIDs, token.Conversations.UpdatedAt, conversationAfter, until, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+	if len(conversations) > 0 {
+		last := conversations[len(conversations)-1]
+		token.Conversations = models.SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID.Hex()}
+	}
+	page.Changes.Conversations = conversations
+	page.HasMore = page.HasMore || len(conversations) == limit
+
+	// MySQL: pertemanan, keanggotaan grup, dan profil
+	friendshipAfter, err := mysqlCursor(token.Friendships)
+	if err != nil {
+		return nil, err
+	}
+	friendships, err := su.friendshipRepo.FindChangedSince(userID, token.Friendships.UpdatedAt, friendshipAfter, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(friendships) > 0 {
+		last := friendships[len(friendships)-1]
+		token.Friendships = models.SyncCursor{UpdatedAt: last.UpdatedAt, ID: strconv.FormatUint(uint64(last.ID), 10)}
+	}
+	page.Changes.Friendships = friendships
+	page.HasMore = page.HasMore || len(friendships) == limit
+
+	memberAfter, err := mysqlCursor(token.GroupMembers)
+	if err != nil {
+		return nil, err
+	}
+	members, err := su.groupRepo.FindMembershipsChangedSince(userID, groupIDs, token.GroupMembers.UpdatedAt, memberAfter, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 0 {
+		last := members[len(members)-1]
+		token.GroupMembers = models.SyncCursor{UpdatedAt: last.UpdatedAt, ID: strconv.FormatUint(uint64(last.ID), 10)}
+	}
+	page.Changes.GroupMembers = members
+	page.HasMore = page.HasMore || len(members) == limit
+
+	// profil yang relevan: diri sendiri, teman, dan sesama anggota grup
+	friendIDs, err := su.friendshipRepo.FriendIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := su.groupRepo.MemberIDsOfGroups(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := append(append([]uint{userID}, friendIDs...), memberIDs...)
+
+	userAfter, err := mysqlCursor(token.Users)
+	if err != nil {
+		return nil, err
+	}
+	users, err := su.userRepo.FindChangedSince(userIDs, token.Users.UpdatedAt, userAfter, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		last := users[len(users)-1]
+		token.Users = models.SyncCursor{UpdatedAt: last.UpdatedAt, ID: strconv.FormatUint(uint64(last.ID), 10)}
+	}
+	// status dan last seen user yang memblokir user ini atau menyembunyikannya lewat
+	// pengaturan privasi disembunyikan
+	blockerIDs, err := su.friendshipRepo.BlockerIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if containsID(blockerIDs, users[i].ID) {
+			users[i].HidePresence()
+		}
+	}
+	if err := su.privacyUseCase.FilterPresence(userID, users); err != nil {
+		return nil, err
+	}
+	page.Changes.Users = users
+	page.HasMore = page.HasMore || len(users) == limit
+
+	if page.NextToken, err = encodeSyncToken(token); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"fmt"
+	"time"
+)
+
+const (
+	typingRateLimit  = 30
+	typingRateWindow = 10 * time.Second
+)
+
+type TypingUseCase struct {
+	typingRepo        repositories.TypingRepository
+	conversationRepo  repositories.ConversationRepository
+	groupRepo         repositories.GroupRepository
+	friendshipRepo    repositories.FriendshipRepository
+	permissionUseCase *GroupPermissionUseCase
+	publisher         EventPublisher
+	ttl               time.Duration
+}
+
+func NewTypingUseCase(typingRepo repositories.TypingRepository, conversationRepo repositories.ConversationRepository, groupRepo repositories.GroupRepository, friendshipRepo repositories.FriendshipRepository, permissionUseCase *GroupPermissionUseCase, publisher EventPublisher, ttl time.Duration) *TypingUseCase {
+	return &TypingUseCase{
+		typingRepo:        typingRepo,
+		conversationRepo:  conversationRepo,
+		groupRepo:         groupRepo,
+		friendshipRepo:    friendshipRepo,
+		permissionUseCase: permissionUseCase,
+		publisher:         publisher,
+		ttl:               ttl,
+	}
+}
+
+// SetTyping menyimpan sinyal typing start/stop dan mengirimkannya ke participant lain.
+// Sinyal start yang berulang hanya di-broadcast ulang saat setengah TTL sudah lewat.
+// Di grup, hanya anggota dengan permission send_messages yang bisa mengirim sinyal typing,
+// dan di DM sinyal ditolak jika salah satu user memblokir yang lain.
+func (tu *TypingUseCase) SetTyping(userID uint, conversationID string, typing bool) error {
+	conversation, err := findMemberConversation(tu.conversationRepo, tu.permissionUseCase, userID, conversationID, models.PermissionSendMessages)
+	if err != nil {
+		return err
+	}
+	if err := checkDMBlock(tu.friendshipRepo, conversation, userID); err != nil {
+		return err
+	}
+
+	allowed, err := config.Cache.AllowRate(context.Background(), fmt.Sprintf("typing:%d", userID), typingRateLimit, typingRateWindow)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrRateLimited
+	}
+
+	payload := models.TypingEvent{ConversationID: conversationID, UserID: userID, Typing: typing}
+	if typing {
+		previous, err := tu.typingRepo.SetTyping(conversationID, userID, tu.ttl)
+		if err != nil {
+			return err
+		}
+		if !previous.IsZero() && time.Until(previous) > tu.ttl/2 {
+			return nil
+		}
+		expiresAt := time.Now().Add(tu.ttl).UTC()
+		payload.ExpiresAt = &expiresAt
+	} else {
+		wasTyping, err := tu.typingRepo.ClearTyping(conversationID, userID)
+		if err != nil || !wasTyping {
+			return err
+		}
+	}
+
+	members, err := conversationMembers(tu.groupRepo, conversation)
+	if err != nil {
+		return err
+	}
+	tu.publisher.PublishToUsers(exclude(members, userID), models.NewEvent(models.EventTyping, payload))
+	return nil
+}
+
+// ListTyping mengembalikan user lain yang sedang mengetik (untuk client yang baru reconnect)
+func (tu *TypingUseCase) ListTyping(userID uint, conversationID string) ([]models.TypingEvent, error) {
+	if _, err := findMemberConversation(tu.conversationRepo, tu.permissionUseCase, userID, conversationID, ""); err != nil {
+		return nil, err
+	}
+
+	typers, err := tu.typingRepo.ListTyping(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.TypingEvent{}
+	for typerID, expiresAt := range typers {
+		if typerID == userID {
+			continue
+		}
+		expiresAt := expiresAt
+		result = append(result, models.TypingEvent{ConversationID: conversationID, UserID: typerID, Typing: true, ExpiresAt: &expiresAt})
+	}
+	return result, nil
+}
+
+// exclude mengembalikan salinan ids tanpa userID
+func exclude(ids []uint, userID uint) []uint {
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != userID {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"context"
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"log"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const reconcileBatchSize = 200
+
+type UnreadUseCase struct {
+	conversationRepo  repositories.ConversationRepository
+	messageRepo       repositories.MessageRepository
+	groupRepo         repositories.GroupRepository
+	permissionUseCase *GroupPermissionUseCase
+	publisher         EventPublisher
+}
+
+func NewUnreadUseCase(conversationRepo repositories.ConversationRepository, messageRepo repositories.MessageRepository, groupRepo repositories.GroupRepository, permissionUseCase *GroupPermissionUseCase, publisher EventPublisher) *UnreadUseCase {
+	return &UnreadUseCase{
+		conversationRepo:  conversationRepo,
+		messageRepo:       messageRepo,
+		groupRepo:         groupRepo,
+		permissionUseCase: permissionUseCase,
+		publisher:         publisher,
+	}
+}
+
+func (uu *UnreadUseCase) findConversation(userID uint, conversationID string) (*models.Conversation, error) {
+	return findMemberConversation(uu.conversationRepo, uu.permissionUseCase, userID, conversationID, "")
+}
+
+func (uu *UnreadUseCase) members(conversation *models.Conversation) ([]uint, error) {
+	return conversationMembers(uu.groupRepo, conversation)
+}
+
+// recompute menghitung ulang unread dari read watermark (sumber kebenaran)
+// lalu menulis hasilnya ke MongoDB dan Redis jika berbeda.
+func (uu *UnreadUseCase) recompute(ctx context.Context, conversation *models.Conversation, userID uint, cached int64, hasCache bool) (int64, error) {
+	key := strconv.FormatUint(uint64(userID), 10)
+	count, err := uu.messageRepo.CountUnread(conversation, userID, conversation.ReadWatermarks[key])
+	if err != nil {
+		return 0, err
+	}
+
+	if stored, ok := conversation.UnreadCounts[key]; !ok || int64(stored) != count {
+		if err := uu.conversationRepo.SetUnreadCount(conversation.ID, userID, int(count)); err != nil {
+			return 0, err
+		}
+	}
+	if !hasCache || cached != count {
+		if err := config.Cache.SetUnreadCount(ctx, userID, conversation.ID.Hex(), count); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// resolve memakai nilai Redis jika cocok dengan MongoDB, selain itu diperbaiki dari watermark
+func (uu *UnreadUseCase) resolve(ctx context.Context, conversation *models.Conversation, userID uint, cached int64, hasCache bool) (int64, error) {
+	stored, ok := conversation.UnreadCounts[strconv.FormatUint(uint64(userID), 10)]
+	if hasCache && ok && int64(stored) == cached {
+		return cached, nil
+	}
+	return uu.recompute(ctx, conversation, userID, cached, hasCache)
+}
+
+func (uu *UnreadUseCase) GetUnreadCount(userID uint, conversationID string) (int64, error) {
+	conversation, err := uu.findConversation(userID, conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	cached, err := config.Cache.GetUnreadCount(ctx, userID, conversationID)
+	return uu.resolve(ctx, conversation, userID, cached, err == nil)
+}
+
+// MarkAsRead memajukan read watermark, mereset unread count di kedua storage,
+// lalu mengirim read receipt ke participant lain.
+func (uu *UnreadUseCase) MarkAsRead(userID uint, conversationID string) error {
+	conversation, err := uu.findConversation(userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	readAt := time.Now().UTC()
+	if err := uu.conversationRepo.MarkRead(conversation.ID, userID, readAt); err != nil {
+		return err
+	}
+	if err := config.Cache.ResetUnreadCount(context.Background(), userID, conversationID); err != nil {
+		return err
+	}
+
+	members, err := uu.members(conversation)
+	if err != nil {
+		return err
+	}
+	uu.publisher.PublishToUsers(members, models.NewEvent(models.EventReceipt, models.ReceiptEvent{
+		ConversationID: conversationID,
+		UserID:         userID,
+		ReadAt:         readAt,
+	}))
+	return nil
+}
+
+// TotalUnread menjumlahkan unread semua conversation user (untuk badge icon aplikasi)
+func (uu *UnreadUseCase) TotalUnread(userID uint) (int64, error) {
+	groupIDs, err := uu.groupRepo.GroupIDsForUser(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	conversations, err := uu.conversationRepo.FindByMember(userID, groupIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, len(conversations))
+	for i, conversation := range conversations {
+		ids[i] = conversation.ID.Hex()
+	}
+
+	ctx := context.Background()
+	cached, err := config.Cache.GetUnreadCounts(ctx, userID, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for i := range conversations {
+		value, hasCache := cached[ids[i]]
+		count, err := uu.resolve(ctx, &conversations[i], userID, value, hasCache)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Reconcile menghitung ulang unread setiap participant di semua conversation
+// dan mengembalikan jumlah counter yang diperbaiki.
+func (uu *UnreadUseCase) Reconcile() (int, error) {
+	ctx := context.Background()
+	repaired := 0
+	lastID := primitive.NilObjectID
+
+	for {
+		conversations, err := uu.conversationRepo.FindBatch(lastID, reconcileBatchSize)
+		if err != nil {
+			return repaired, err
+		}
+		if len(conversations) == 0 {
+			return repaired, nil
+		}
+
+		for i := range conversations {
+			conversation := &conversations[i]
+			lastID = conversation.ID
+
+			members, err := uu.members(conversation)
+			if err != nil {
+				return repaired, err
+			}
+
+			conversationID := conversation.ID.Hex()
+			for _, member := range members {
+				cached, err := config.Cache.GetUnreadCount(ctx, member, conversationID)
+				hasCache := err == nil
+				stored, hasStored := conversation.UnreadCounts[strconv.FormatUint(uint64(member), 10)]
+
+				count, err := uu.recompute(ctx, conversation, member, cached, hasCache)
+				if err != nil {
+					return repaired, err
+				}
+				if !hasCache || cached != count || !hasStored || int64(stored) != count {
+					repaired++
+				}
+			}
+		}
+	}
+}
+
+// StartReconciler menjalankan Reconcile secara berkala di background
+func (uu *UnreadUseCase) StartReconciler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			repaired, err := uu.Reconcile()
+			if err != nil {
+				log.Printf("Unread reconciliation failed: %v", err)
+				continue
+			}
+			if repaired > 0 {
+				log.Printf("Unread reconciliation repaired %d counters", repaired)
+			}
+		}
+	}()
+}
@@ -3,15 +3,29 @@ package usecases
 import (
 	"echo-chat-app-backend/internal/models"
 	"echo-chat-app-backend/internal/repositories"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
 )
 
 type UserUseCase struct {
-	userRepo repositories.UserRepository
+	userRepo       repositories.UserRepository
+	friendshipRepo repositories.FriendshipRepository
+	privacyUseCase *PrivacyUseCase
+	groupUseCase   *GroupUseCase
+	publisher      EventPublisher
+	sessions       SessionCloser
 }
 
-func NewUserUseCase(userRepo repositories.UserRepository) *UserUseCase {
+func NewUserUseCase(userRepo repositories.UserRepository, friendshipRepo repositories.FriendshipRepository, privacyUseCase *PrivacyUseCase, groupUseCase *GroupUseCase, publisher EventPublisher, sessions SessionCloser) *UserUseCase {
 	return &UserUseCase{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		privacyUseCase: privacyUseCase,
+		groupUseCase:   groupUseCase,
+		publisher:      publisher,
+		sessions:       sessions,
 	}
 }
 
@@ -19,10 +33,83 @@ func (uc *UserUseCase) Me(uid string) (*models.User, error) {
 	return uc.userRepo.Me(uid)
 }
 
-func (uc *UserUseCase) SearchUserByUsername(username string) (*models.User, error) {
-	return uc.userRepo.SearchUserByUsername(username)
+// SearchUserByUsername mencari user berdasarkan username. User yang memblokir pencari
+// diperlakukan seperti tidak ada, dan presence disaring menurut pengaturan privasi.
+func (uc *UserUseCase) SearchUserByUsername(userID uint, username string) (*models.User, error) {
+	user, err := uc.userRepo.SearchUserByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blockerIDs, err := uc.friendshipRepo.BlockerIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if containsID(blockerIDs, user.ID) {
+		return nil, ErrNotFound
+	}
+
+	users := []models.User{*user}
+	if err := uc.privacyUseCase.FilterPresence(userID, users); err != nil {
+		return nil, err
+	}
+	return &users[0], nil
 }
 
 func (uc *UserUseCase) UpdateProfile(uid, name, username, avatar_url string) (*models.User, error) {
 	return uc.userRepo.UpdateProfile(uid, name, username, avatar_url)
 }
+
+// DeleteAccount menghapus akun user. Keluar dari semua grup (kepemilikan pindah ke anggota
+// lain), penghapusan pertemanan dan penghapusan user di mysql berjalan dalam satu transaksi.
+// Akun Firebase baru dihapus setelah transaksi berhasil, lalu semua koneksi realtime user ditutup.
+func (uc *UserUseCase) DeleteAccount(userID uint, uid string) error {
+	groups, err := uc.groupUseCase.memberGroups(userID)
+	if err != nil {
+		return err
+	}
+
+	deletion, err := uc.userRepo.Delete(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	for groupID, result := range deletion.Groups {
+		group, ok := groups[groupID]
+		if !ok {
+			// user bergabung setelah daftar grup diambil
+			continue
+		}
+		if err := uc.groupUseCase.left(userID, group.group, group.members, result); err != nil {
+			log.Printf("Failed to notify group %d about deleted user %d: %v", groupID, userID, err)
+		}
+	}
+	for _, friendship := range deletion.Friendships {
+		// blokir tidak pernah diberitahukan ke user yang diblokir
+		otherID := friendship.FriendID
+		if otherID == userID {
+			otherID = friendship.UserID
+		}
+		if friendship.Status == models.FriendshipBlocked && friendship.UserID != otherID {
+			continue
+		}
+		uc.publisher.PublishToUsers([]uint{otherID}, models.NewEvent(models.EventFriendship, models.FriendshipEvent{
+			Action:     models.FriendshipActionRemoved,
+			Friendship: friendship,
+		}))
+	}
+	uc.sessions.CloseUser(userID)
+
+	// akun mysql sudah terhapus; jika gagal di sini, login berikutnya dengan akun Firebase
+	// yang tersisa hanya membuat user baru
+	if err := uc.userRepo.DeleteFirebaseUser(uid); err != nil {
+		log.Printf("Failed to delete firebase user %s of deleted user %d: %v", uid, userID, err)
+	}
+	return nil
+}
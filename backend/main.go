@@ -44,7 +44,7 @@ func main() {
 		})
 	})
 
-	r := routes.SetupRouter(config.DB.MySQL, config.FirebaseAuth)
+	r := routes.SetupRouter(config.DB.MySQL, config.DB.MongoDB, config.DB.Redis, config.FirebaseAuth)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/usecases"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// One-off migration: tambahkan kolom email_hash/discoverable pada users lalu isi email_hash
+// semua user dengan CONTACT_DISCOVERY_SALT. Jalankan ulang setiap kali salt diganti.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	db := config.DB.MySQL
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		log.Fatalf("❌ Failed to migrate users: %v", err)
+	}
+
+	salt := os.Getenv("CONTACT_DISCOVERY_SALT")
+	updated := 0
+	users := []models.User{}
+	result := db.Select("id", "email", "email_hash").FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			hash := usecases.HashEmail(salt, user.Email)
+			if user.EmailHash == hash {
+				continue
+			}
+			if err := db.Model(&user).UpdateColumn("email_hash", hash).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		log.Fatalf("❌ Failed to backfill email hashes: %v", result.Error)
+	}
+
+	log.Printf("✅ Migration finished, %d email hashes updated", updated)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// One-off migration: tambahkan kolom updated_at/deleted_at pada friendships dan group_members,
+// isi updated_at untuk baris lama, lalu buat index MongoDB yang dipakai /sync.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	db := config.DB.MySQL
+	if err := db.AutoMigrate(&models.Friendship{}, &models.GroupMember{}); err != nil {
+		log.Fatalf("❌ Failed to migrate tables: %v", err)
+	}
+
+	// baris tanpa updated_at tidak akan pernah terambil oleh query perubahan
+	friendships := db.Exec("UPDATE friendships SET updated_at = COALESCE(accepted_at, created_at) WHERE updated_at IS NULL")
+	if friendships.Error != nil {
+		log.Fatalf("❌ Failed to backfill friendships: %v", friendships.Error)
+	}
+	members := db.Exec("UPDATE group_members SET updated_at = joined_at WHERE updated_at IS NULL")
+	if members.Error != nil {
+		log.Fatalf("❌ Failed to backfill group members: %v", members.Error)
+	}
+
+	if err := repositories.NewMessageRepository(config.DB.MongoDB).EnsureIndexes(); err != nil {
+		log.Fatalf("❌ Failed to create message indexes: %v", err)
+	}
+	if err := repositories.NewConversationRepository(config.DB.MongoDB).EnsureIndexes(); err != nil {
+		log.Fatalf("❌ Failed to create conversation indexes: %v", err)
+	}
+
+	log.Printf("✅ Migration finished, %d friendships and %d group members backfilled", friendships.RowsAffected, members.RowsAffected)
+}
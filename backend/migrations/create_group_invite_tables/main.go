@@ -0,0 +1,32 @@
+package main
+
+import (
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// One-off migration: buat tabel group_invites dan group_invite_uses untuk invite link grup.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	db := config.DB.MySQL
+	err := db.AutoMigrate(
+		&models.GroupInvite{},
+		&models.GroupInviteUse{},
+	)
+	if err != nil {
+		log.Fatalf("❌ Failed to migrate group invite tables: %v", err)
+	}
+
+	log.Println("✅ Migration finished")
+}
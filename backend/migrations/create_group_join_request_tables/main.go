@@ -0,0 +1,33 @@
+package main
+
+import (
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// One-off migration: buat tabel group_join_requests, lalu tambahkan kolom join_questions/is_open
+// pada groups.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	db := config.DB.MySQL
+	err := db.AutoMigrate(
+		&models.Group{},
+		&models.GroupJoinRequest{},
+	)
+	if err != nil {
+		log.Fatalf("❌ Failed to migrate group join request tables: %v", err)
+	}
+
+	log.Println("✅ Migration finished")
+}
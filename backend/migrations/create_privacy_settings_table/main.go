@@ -0,0 +1,31 @@
+package main
+
+import (
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// One-off migration: buat tabel privacy_settings untuk pengaturan privasi user.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	db := config.DB.MySQL
+	err := db.AutoMigrate(
+		&models.PrivacySettings{},
+	)
+	if err != nil {
+		log.Fatalf("❌ Failed to migrate privacy settings: %v", err)
+	}
+
+	log.Println("✅ Migration finished")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"echo-chat-app-backend/config"
+	"echo-chat-app-backend/internal/models"
+	"echo-chat-app-backend/internal/repositories"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var dryRun = flag.Bool("dry-run", false, "Only report duplicate DM conversations without changing data")
+
+// One-off migration: isi dm_key untuk semua conversation DM, gabungkan duplikat
+// untuk pasangan user yang sama, lalu buat unique index pada dm_key.
+func main() {
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if err := config.InitDatabases(); err != nil {
+		log.Fatalf("❌ Failed to initialize databases: %v", err)
+	}
+	defer config.CloseDatabases()
+
+	log.Println("🔄 Merging duplicate DM conversations...")
+	merged, err := mergeDuplicates(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Migration failed: %v", err)
+	}
+
+	if *dryRun {
+		log.Printf("ℹ️  Dry run: %d DM pairs would be merged", merged)
+		return
+	}
+
+	if err := repositories.NewConversationRepository(config.DB.MongoDB).EnsureIndexes(); err != nil {
+		log.Fatalf("❌ Failed to create indexes: %v", err)
+	}
+
+	log.Printf("✅ Migration finished, %d DM pairs merged", merged)
+}
+
+func mergeDuplicates(ctx context.Context) (int, error) {
+	collection := config.DB.MongoDB.Collection(models.Conversation{}.CollectionName())
+
+	cursor, err := collection.Find(ctx, bson.M{"group_id": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+
+	conversations := []models.Conversation{}
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return 0, err
+	}
+
+	// kelompokkan berdasarkan pasangan participant yang sudah diurutkan
+	byKey := map[string][]models.Conversation{}
+	for _, conversation := range conversations {
+		participants := uniqueSorted(conversation.Participants)
+		if len(participants) != 2 {
+			log.Printf("⚠️  Skipping conversation %s with %d participants", conversation.ID.Hex(), len(participants))
+			continue
+		}
+		key := models.DMKey(participants[0], participants[1])
+		byKey[key] = append(byKey[key], conversation)
+	}
+
+	merged := 0
+	for key, group := range byKey {
+		if len(group) > 1 {
+			merged++
+			log.Printf("🔗 %s has %d conversations", key, len(group))
+		}
+		if *dryRun {
+			continue
+		}
+
+		if err := mergeGroup(ctx, key, group); err != nil {
+			return merged, fmt.Errorf("failed to merge %s: %w", key, err)
+		}
+	}
+	return merged, nil
+}
+
+// mergeGroup menyimpan conversation tertua sebagai survivor dan menghapus sisanya.
+// Pesan tidak menyimpan conversation id, jadi cukup ringkasannya yang digabung.
+func mergeGroup(ctx context.Context, key string, group []models.Conversation) error {
+	collection := config.DB.MongoDB.Collection(models.Conversation{}.CollectionName())
+
+	sort.Slice(group, func(i, j int) bool {
+		if group[i].CreatedAt.Equal(group[j].CreatedAt) {
+			return group[i].ID.Hex() < group[j].ID.Hex()
+		}
+		return group[i].CreatedAt.Before(group[j].CreatedAt)
+	})
+
+	survivor := group[0]
+	survivor.Participants = uniqueSorted(survivor.Participants)
+	survivor.DMKey = key
+	survivor.UpdatedAt = time.Now().UTC()
+	if survivor.UnreadCounts == nil {
+		survivor.UnreadCounts = map[string]int{}
+	}
+	if survivor.ReadWatermarks == nil {
+		survivor.ReadWatermarks = map[string]time.Time{}
+	}
+
+	duplicateIDs := []primitive.ObjectID{}
+	for _, duplicate := range group[1:] {
+		duplicateIDs = append(duplicateIDs, duplicate.ID)
+
+		if duplicate.LastMessageAt.After(survivor.LastMessageAt) {
+			survivor.LastMessageID = duplicate.LastMessageID
+			survivor.LastMessageText = duplicate.LastMessageText
+			survivor.LastMessageAt = duplicate.LastMessageAt
+			survivor.LastSenderID = duplicate.LastSenderID
+		}
+		for user, count := range duplicate.UnreadCounts {
+			if count > survivor.UnreadCounts[user] {
+				survivor.UnreadCounts[user] = count
+			}
+		}
+		for user, readAt := range duplicate.ReadWatermarks {
+			if readAt.After(survivor.ReadWatermarks[user]) {
+				survivor.ReadWatermarks[user] = readAt
+			}
+		}
+	}
+
+	if _, err := collection.ReplaceOne(ctx, bson.M{"_id": survivor.ID}, survivor); err != nil {
+		return err
+	}
+	if len(duplicateIDs) == 0 {
+		return nil
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicateIDs}}); err != nil {
+		return err
+	}
+
+	// Hapus unread count di Redis agar dihitung ulang dari read watermark saat dibaca
+	keys := []string{}
+	for _, conversation := range group {
+		for _, user := range survivor.Participants {
+			keys = append(keys, fmt.Sprintf("user:%d:unread:%s", user, conversation.ID.Hex()))
+		}
+	}
+	return config.Cache.Delete(ctx, keys...)
+}
+
+func uniqueSorted(ids []uint) []uint {
+	seen := map[uint]bool{}
+	result := []uint{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
@@ -125,8 +125,13 @@ func recreateDatabase() error {
 func dropAllTables() error {
 	log.Println("🗑️  Dropping all tables and collections...")
 	err := config.DB.MySQL.Migrator().DropTable(
+		&models.GroupJoinRequest{},
+		&models.GroupInviteUse{},
+		&models.GroupInvite{},
+		&models.GroupPermissionOverride{},
 		&models.GroupMember{},
 		&models.Group{},
+		&models.PrivacySettings{},
 		&models.Friendship{},
 		&models.User{},
 	)
@@ -148,8 +153,13 @@ func runMigrations() error {
 	return config.DB.MySQL.AutoMigrate(
 		&models.User{},
 		&models.Friendship{},
+		&models.PrivacySettings{},
 		&models.Group{},
 		&models.GroupMember{},
+		&models.GroupPermissionOverride{},
+		&models.GroupInvite{},
+		&models.GroupInviteUse{},
+		&models.GroupJoinRequest{},
 	)
 }
 